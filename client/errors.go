@@ -0,0 +1,86 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ErrorCode is the stable code of the error response
+type ErrorCode string
+
+// error codes
+const (
+	ErrorCodeInternal               ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeUnknownFunction        ErrorCode = "UNKNOWN_FUNCTION"
+	ErrorCodeInvalidParameters      ErrorCode = "INVALID_PARAMETERS"
+	ErrorCodeAuthenticationFailed   ErrorCode = "AUTHENTICATION_FAILED"
+	ErrorCodeInvalidAccess          ErrorCode = "INVALID_ACCESS"
+	ErrorCodeNotAdministrator       ErrorCode = "NOT_ADMINISTRATOR"
+	ErrorCodeInvalidConfig          ErrorCode = "INVALID_CONFIG"
+	ErrorCodeContractNotFound       ErrorCode = "CONTRACT_NOT_FOUND"
+	ErrorCodeContractIDCollided     ErrorCode = "CONTRACT_ID_COLLIDED"
+	ErrorCodeNotEnoughSigners       ErrorCode = "NOT_ENOUGH_SIGNERS"
+	ErrorCodeTooManySigners         ErrorCode = "TOO_MANY_SIGNERS"
+	ErrorCodeInvalidOptions         ErrorCode = "INVALID_OPTIONS"
+	ErrorCodeInvalidDocument        ErrorCode = "INVALID_DOCUMENT"
+	ErrorCodeInvalidSelector        ErrorCode = "INVALID_SELECTOR"
+	ErrorCodeUnsupported            ErrorCode = "UNSUPPORTED"
+	ErrorCodeAlreadyApproved        ErrorCode = "ALREADY_APPROVED"
+	ErrorCodeAlreadyDisapproved     ErrorCode = "ALREADY_DISAPPROVED"
+	ErrorCodeAlreadyCancelRequested ErrorCode = "ALREADY_CANCEL_REQUESTED"
+	ErrorCodeAlreadyExecuted        ErrorCode = "ALREADY_EXECUTED"
+	ErrorCodeAlreadyCanceled        ErrorCode = "ALREADY_CANCELED"
+	ErrorCodeAlreadyFinished        ErrorCode = "ALREADY_FINISHED"
+	ErrorCodeExpired                ErrorCode = "EXPIRED"
+	ErrorCodeFrozen                 ErrorCode = "FROZEN"
+	ErrorCodeAlreadyFrozen          ErrorCode = "ALREADY_FROZEN"
+	ErrorCodeNotFrozen              ErrorCode = "NOT_FROZEN"
+	ErrorCodeCallbackFailed         ErrorCode = "CALLBACK_FAILED"
+	ErrorCodeNotFailed              ErrorCode = "NOT_FAILED"
+	ErrorCodeUnregisteredChaincode  ErrorCode = "UNREGISTERED_CHAINCODE"
+	ErrorCodeAlreadyRegistered      ErrorCode = "ALREADY_REGISTERED"
+	ErrorCodeSuspendedChaincode     ErrorCode = "SUSPENDED_CHAINCODE"
+	ErrorCodeAlreadySuspended       ErrorCode = "ALREADY_SUSPENDED"
+	ErrorCodeNotSuspended           ErrorCode = "NOT_SUSPENDED"
+	ErrorCodeAlreadyMigrated        ErrorCode = "ALREADY_MIGRATED"
+	ErrorCodePublicKeyNotFound      ErrorCode = "PUBLIC_KEY_NOT_FOUND"
+	ErrorCodeInvalidPublicKey       ErrorCode = "INVALID_PUBLIC_KEY"
+	ErrorCodeInvalidSignature       ErrorCode = "INVALID_SIGNATURE"
+)
+
+// Error is the JSON body of the error response message
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// Error implements error interface
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+// ParseError decodes the message of the error response.
+// If it isn't the JSON body (ex. errors of the peer), the code is empty and the message is kept.
+func ParseError(msg string) *Error {
+	// the peer can prefix the chaincode message (ex. 'transaction returned with failure: {...}')
+	if i := strings.Index(msg, "{"); i >= 0 {
+		e := &Error{}
+		if err := json.Unmarshal([]byte(msg[i:]), e); err == nil && e.Code != "" {
+			return e
+		}
+	}
+	return &Error{Message: msg}
+}
+
+// IsErrorCode returns true if the error (or the error message) has the code
+func IsErrorCode(err error, code ErrorCode) bool {
+	if nil == err {
+		return false
+	}
+	if e, ok := err.(*Error); ok {
+		return e.Code == code
+	}
+	return ParseError(err.Error()).Code == code
+}
@@ -0,0 +1,54 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name        string
+		msg         string
+		wantCode    ErrorCode
+		wantMessage string
+	}{
+		{"coded", `{"code":"EXPIRED","message":"failed to approve|expired"}`, ErrorCodeExpired, "failed to approve|expired"},
+		{"prefixed by the peer", `transaction returned with failure: {"code":"FROZEN","message":"frozen"}`, ErrorCodeFrozen, "frozen"},
+		{"no code", `{"message":"failed"}`, "", `{"message":"failed"}`},
+		{"plain text", "failed to get the config", "", "failed to get the config"},
+		{"broken JSON", `failed {"code":`, "", `failed {"code":`},
+		{"empty", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ParseError(tt.msg)
+			if e.Code != tt.wantCode || e.Message != tt.wantMessage {
+				t.Errorf("ParseError() = {%s, %s}, want {%s, %s}", e.Code, e.Message, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestIsErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code ErrorCode
+		want bool
+	}{
+		{"nil", nil, ErrorCodeExpired, false},
+		{"*Error", &Error{Code: ErrorCodeExpired}, ErrorCodeExpired, true},
+		{"*Error of other code", &Error{Code: ErrorCodeFrozen}, ErrorCodeExpired, false},
+		{"error message", errors.New(`{"code":"EXPIRED","message":"expired"}`), ErrorCodeExpired, true},
+		{"plain error", errors.New("expired"), ErrorCodeExpired, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsErrorCode(tt.err, tt.code); got != tt.want {
+				t.Errorf("IsErrorCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package client
+
+// QueryFunc queries the page of the bookmark, and returns the payload of the response
+type QueryFunc func(bookmark string) ([]byte, error)
+
+// Pager iterates pages of 'list', 'search', etc. by bookmarks
+type Pager struct {
+	query    QueryFunc
+	bookmark string
+	done     bool
+}
+
+// NewPager _
+// If the bookmark is empty, it starts from the first page.
+func NewPager(query QueryFunc, bookmark string) *Pager {
+	return &Pager{query: query, bookmark: bookmark}
+}
+
+// Next returns the next page, it returns nil after the last page.
+// The last page is detected by the empty bookmark or the same bookmark.
+// (CouchDB returns the bookmark even if it's the last page)
+// Pages may be empty before the last page (ex. filtered by CCIDs), so empty pages are skipped.
+func (p *Pager) Next() (*QueryResult, error) {
+	for !p.done {
+		payload, err := p.query(p.bookmark)
+		if err != nil {
+			return nil, err
+		}
+		result, err := DecodeQueryResult(payload)
+		if err != nil {
+			return nil, err
+		}
+		next := result.Bookmark()
+		if next == "" || next == p.bookmark {
+			p.done = true
+		}
+		p.bookmark = next
+		if len(result.Records) > 0 {
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// Bookmark returns the bookmark of the next page
+func (p *Pager) Bookmark() string {
+	return p.bookmark
+}
+
+// ForEachContract iterates all contracts of pages
+func (p *Pager) ForEachContract(fn func(*Contract) error) error {
+	for {
+		result, err := p.Next()
+		if err != nil {
+			return err
+		}
+		if nil == result {
+			return nil
+		}
+		contracts, err := result.Contracts()
+		if err != nil {
+			return err
+		}
+		for _, contract := range contracts {
+			if err = fn(contract); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// page is the fake response of the query, keyed by the requested bookmark
+type page struct {
+	records []string
+	next    string
+}
+
+func fakeQuery(pages map[string]page, requested *[]string) QueryFunc {
+	return func(bookmark string) ([]byte, error) {
+		*requested = append(*requested, bookmark)
+		p, ok := pages[bookmark]
+		if !ok {
+			return nil, errors.New("unexpected bookmark: " + bookmark)
+		}
+		result := &QueryResult{Meta: &QueryMeta{Bookmark: p.next}, Records: []json.RawMessage{}}
+		for _, r := range p.records {
+			result.Records = append(result.Records, json.RawMessage(`"`+r+`"`))
+		}
+		return json.Marshal(result)
+	}
+}
+
+func TestPagerNext(t *testing.T) {
+	tests := []struct {
+		name          string
+		pages         map[string]page
+		bookmark      string
+		wantRecords   []string
+		wantRequested []string
+		wantErr       bool
+	}{
+		{
+			name:          "single page",
+			pages:         map[string]page{"": {[]string{"a", "b"}, ""}},
+			wantRecords:   []string{"a", "b"},
+			wantRequested: []string{""},
+		},
+		{
+			name:          "pages until the empty bookmark",
+			pages:         map[string]page{"": {[]string{"a"}, "1"}, "1": {[]string{"b"}, "2"}, "2": {[]string{"c"}, ""}},
+			wantRecords:   []string{"a", "b", "c"},
+			wantRequested: []string{"", "1", "2"},
+		},
+		{
+			name:          "the same bookmark (CouchDB last page)",
+			pages:         map[string]page{"": {[]string{"a"}, "x"}, "x": {[]string{"b"}, "x"}},
+			wantRecords:   []string{"a", "b"},
+			wantRequested: []string{"", "x"},
+		},
+		{
+			name:          "empty pages before the last page",
+			pages:         map[string]page{"": {[]string{"a"}, "1"}, "1": {[]string{}, "2"}, "2": {[]string{}, "3"}, "3": {[]string{"b"}, ""}},
+			wantRecords:   []string{"a", "b"},
+			wantRequested: []string{"", "1", "2", "3"},
+		},
+		{
+			name:          "empty last page",
+			pages:         map[string]page{"": {[]string{"a"}, "1"}, "1": {[]string{}, ""}},
+			wantRecords:   []string{"a"},
+			wantRequested: []string{"", "1"},
+		},
+		{
+			name:          "no records",
+			pages:         map[string]page{"": {[]string{}, ""}},
+			wantRecords:   []string{},
+			wantRequested: []string{""},
+		},
+		{
+			name:          "start from the bookmark",
+			pages:         map[string]page{"1": {[]string{"b"}, ""}},
+			bookmark:      "1",
+			wantRecords:   []string{"b"},
+			wantRequested: []string{"1"},
+		},
+		{
+			name:          "query error",
+			pages:         map[string]page{"": {[]string{"a"}, "1"}},
+			wantRecords:   []string{"a"},
+			wantRequested: []string{"", "1"},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested := []string{}
+			pager := NewPager(fakeQuery(tt.pages, &requested), tt.bookmark)
+			records := []string{}
+			var err error
+			for {
+				var result *QueryResult
+				if result, err = pager.Next(); err != nil || nil == result {
+					break
+				}
+				if len(result.Records) == 0 {
+					t.Fatal("Next() returned the empty page")
+				}
+				for _, r := range result.Records {
+					s := ""
+					json.Unmarshal(r, &s)
+					records = append(records, s)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Next() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(records, tt.wantRecords) {
+				t.Errorf("records = %v, want %v", records, tt.wantRecords)
+			}
+			if !reflect.DeepEqual(requested, tt.wantRequested) {
+				t.Errorf("requested bookmarks = %v, want %v", requested, tt.wantRequested)
+			}
+			if !tt.wantErr {
+				if result, err := pager.Next(); result != nil || err != nil {
+					t.Errorf("Next() after the last page = %v, %v", result, err)
+				}
+			}
+		})
+	}
+}
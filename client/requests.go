@@ -0,0 +1,373 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+// Package client is the Go client library of kiesnet-contract.
+// It builds requests (function and arguments), decodes responses and error codes.
+// Function names are shared with the routes of the chaincode, so they are kept in lockstep.
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// function names (routes of the chaincode)
+const (
+	FnAdminCancel    = "admin/cancel"
+	FnAdminFreeze    = "admin/freeze"
+	FnAdminUnfreeze  = "admin/unfreeze"
+	FnApprove        = "approve"
+	FnCallbackAllow  = "callback/allow"
+	FnCallbackGet    = "callback/get"
+	FnCallbackSet    = "callback/set"
+	FnCallbacks      = "callbacks"
+	FnCancel         = "cancel"
+	FnCCIDGet        = "ccid/get"
+	FnCCIDList       = "ccid/list"
+	FnCCIDRegister   = "ccid/register"
+	FnCCIDResume     = "ccid/resume"
+	FnCCIDSuspend    = "ccid/suspend"
+	FnCCIDUpdate     = "ccid/update"
+	FnConfig         = "config"
+	FnConfigSet      = "config/set"
+	FnContractStatus = "contract/status"
+	FnCreate         = "create"
+	FnDetail         = "detail"
+	FnDisapprove     = "disapprove"
+	FnExecute        = "execute"
+	FnGet            = "get"
+	FnHistory        = "history"
+	FnKeyGet         = "key/get"
+	FnKeyRegister    = "key/register"
+	FnList           = "list"
+	FnMigrate        = "migrate"
+	FnMigrateStatus  = "migrate/status"
+	FnRetryExecute   = "retry_execute"
+	FnSearch         = "search"
+	FnSummary        = "summary"
+	FnVer            = "ver"
+)
+
+// TransientPIN is the transient key of the kiesnet-id PIN
+const TransientPIN = "kiesnet-id/pin"
+
+// list options
+const (
+	ListFinished    = "finished"
+	ListUnfinished  = "unfinished"
+	ListApproved    = "approved"
+	ListUnsigned    = "unsigned"
+	ListExecuted    = "executed"
+	ListCanceled    = "canceled"
+	ListDisapproved = "disapproved"
+	ListExpired     = "expired"
+	ListAll         = "all"
+)
+
+// cancel policies
+const (
+	CancelPolicySigner  = "signer"
+	CancelPolicyCreator = "creator"
+	CancelPolicyQuorum  = "quorum"
+	CancelPolicyCCID    = "ccid"
+)
+
+// Request is the invocation of the chaincode function
+type Request struct {
+	Function  string
+	Args      []string
+	Transient map[string][]byte
+}
+
+// NewRequest _
+func NewRequest(fn string, args ...string) *Request {
+	return &Request{Function: fn, Args: args}
+}
+
+// WithPIN sets the PIN of kiesnet-id to the transient
+func (r *Request) WithPIN(pin string) *Request {
+	if nil == r.Transient {
+		r.Transient = map[string][]byte{}
+	}
+	r.Transient[TransientPIN] = []byte(pin)
+	return r
+}
+
+// ArgsBytes returns arguments as bytes (without the function name)
+func (r *Request) ArgsBytes() [][]byte {
+	args := make([][]byte, len(r.Args))
+	for i, arg := range r.Args {
+		args[i] = []byte(arg)
+	}
+	return args
+}
+
+// InvocationArgs returns the function name and arguments as bytes, for chaincode to chaincode invocations
+func (r *Request) InvocationArgs() [][]byte {
+	return append([][]byte{[]byte(r.Function)}, r.ArgsBytes()...)
+}
+
+// CancelPolicy is the cancel policy option of 'create'
+type CancelPolicy struct {
+	Type      string `json:"type"`
+	Threshold int    `json:"threshold,omitempty"` // quorum only
+}
+
+// CreateRequest is the request builder of 'create'
+type CreateRequest struct {
+	Document     interface{}   // JSON string, []byte or a value marshaled to JSON
+	Expiry       int64         // seconds, 0 means the default
+	CancelPolicy *CancelPolicy // nil means the default (signer)
+	Signers      []string      // KIDs of signers, excluding the creator
+	// nil means the target registered by 'callback/set', empty fields are defaults
+	CallbackTarget *CallbackTarget
+}
+
+// Build _
+func (c *CreateRequest) Build() (*Request, error) {
+	var document string
+	switch d := c.Document.(type) {
+	case string:
+		document = d
+	case []byte:
+		document = string(d)
+	default:
+		data, err := json.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		document = string(data)
+	}
+
+	expiry := strconv.FormatInt(c.Expiry, 10)
+	if c.CancelPolicy != nil || c.CallbackTarget != nil {
+		data, err := json.Marshal(struct {
+			Expiry         int64           `json:"expiry"`
+			CancelPolicy   *CancelPolicy   `json:"cancel_policy,omitempty"`
+			CallbackTarget *CallbackTarget `json:"callback_target,omitempty"`
+		}{c.Expiry, c.CancelPolicy, c.CallbackTarget})
+		if err != nil {
+			return nil, err
+		}
+		expiry = string(data)
+	}
+
+	args := append([]string{document, expiry}, c.Signers...)
+	return NewRequest(FnCreate, args...), nil
+}
+
+// NewApproveRequest _
+func NewApproveRequest(id, comment string) *Request {
+	return NewRequest(FnApprove, id, comment)
+}
+
+// NewDisapproveRequest _
+func NewDisapproveRequest(id, comment string) *Request {
+	return NewRequest(FnDisapprove, id, comment)
+}
+
+// NewCancelRequest _
+func NewCancelRequest(id string) *Request {
+	return NewRequest(FnCancel, id)
+}
+
+// NewExecuteRequest _
+func NewExecuteRequest(id string) *Request {
+	return NewRequest(FnExecute, id)
+}
+
+// NewRetryExecuteRequest _
+func NewRetryExecuteRequest(id string) *Request {
+	return NewRequest(FnRetryExecute, id)
+}
+
+// NewAdminCancelRequest _
+func NewAdminCancelRequest(id, reason string) *Request {
+	return NewRequest(FnAdminCancel, id, reason)
+}
+
+// NewAdminFreezeRequest _
+func NewAdminFreezeRequest(id, reason string) *Request {
+	return NewRequest(FnAdminFreeze, id, reason)
+}
+
+// NewAdminUnfreezeRequest _
+func NewAdminUnfreezeRequest(id, reason string) *Request {
+	return NewRequest(FnAdminUnfreeze, id, reason)
+}
+
+// NewKeyGetRequest _
+// Empty kid means the invoker.
+func NewKeyGetRequest(kid string) *Request {
+	if kid == "" {
+		return NewRequest(FnKeyGet)
+	}
+	return NewRequest(FnKeyGet, kid)
+}
+
+// NewKeyRegisterRequest _
+// The public key is PEM or base64 encoded PKIX (DER), ECDSA or Ed25519.
+func NewKeyRegisterRequest(publicKey string) *Request {
+	return NewRequest(FnKeyRegister, publicKey)
+}
+
+// NewGetRequest _
+func NewGetRequest(id string) *Request {
+	return NewRequest(FnGet, id)
+}
+
+// NewDetailRequest _
+func NewDetailRequest(id string) *Request {
+	return NewRequest(FnDetail, id)
+}
+
+// NewHistoryRequest _
+func NewHistoryRequest(id string) *Request {
+	return NewRequest(FnHistory, id)
+}
+
+// NewCallbacksRequest _
+func NewCallbacksRequest(id string) *Request {
+	return NewRequest(FnCallbacks, id)
+}
+
+// NewContractStatusRequest _
+func NewContractStatusRequest(id string) *Request {
+	return NewRequest(FnContractStatus, id)
+}
+
+// NewCallbackGetRequest _
+func NewCallbackGetRequest(ccid string) *Request {
+	return NewRequest(FnCallbackGet, ccid)
+}
+
+// NewCallbackSetRequest _
+// Empty chaincode means the invoker chaincode, empty channel means the same channel, empty functions mean defaults. (approve is disabled)
+// Other chaincodes must allow the invoker chaincode by 'callback/allow'.
+func NewCallbackSetRequest(chaincode, channel, execute, cancel, approve string) *Request {
+	return NewRequest(FnCallbackSet, chaincode, channel, execute, cancel, approve)
+}
+
+// NewCallbackAllowRequest _
+// Empty ccids means none.
+func NewCallbackAllowRequest(ccids []string) *Request {
+	return NewRequest(FnCallbackAllow, strings.Join(ccids, ","))
+}
+
+// RegistrationLimits is the limits of 'ccid/register' and 'ccid/update'
+type RegistrationLimits struct {
+	MaxSigners        int      `json:"max_signers,omitempty"`        // 0 means 'max_signers' of the config
+	MaxDocumentSize   int      `json:"max_document_size,omitempty"`  // bytes, 0 means unlimited
+	CallbackFunctions []string `json:"callback_functions,omitempty"` // empty means any
+}
+
+// NewCCIDGetRequest _
+func NewCCIDGetRequest(ccid string) *Request {
+	return NewRequest(FnCCIDGet, ccid)
+}
+
+// NewCCIDListRequest _
+func NewCCIDListRequest(bookmark string) *Request {
+	return NewRequest(FnCCIDList, bookmark)
+}
+
+// NewCCIDRegisterRequest _
+// nil limits means defaults.
+func NewCCIDRegisterRequest(ccid string, limits *RegistrationLimits) (*Request, error) {
+	if nil == limits {
+		return NewRequest(FnCCIDRegister, ccid), nil
+	}
+	data, err := json.Marshal(limits)
+	if err != nil {
+		return nil, err
+	}
+	return NewRequest(FnCCIDRegister, ccid, string(data)), nil
+}
+
+// NewCCIDUpdateRequest _
+// The limits replace all limits of the registration.
+func NewCCIDUpdateRequest(ccid string, limits *RegistrationLimits) (*Request, error) {
+	if nil == limits {
+		limits = &RegistrationLimits{}
+	}
+	data, err := json.Marshal(limits)
+	if err != nil {
+		return nil, err
+	}
+	return NewRequest(FnCCIDUpdate, ccid, string(data)), nil
+}
+
+// NewCCIDSuspendRequest _
+func NewCCIDSuspendRequest(ccid, reason string) *Request {
+	return NewRequest(FnCCIDSuspend, ccid, reason)
+}
+
+// NewCCIDResumeRequest _
+func NewCCIDResumeRequest(ccid string) *Request {
+	return NewRequest(FnCCIDResume, ccid)
+}
+
+// NewConfigRequest _
+func NewConfigRequest() *Request {
+	return NewRequest(FnConfig)
+}
+
+// NewConfigSetRequest _
+// The config is the JSON object of fields to change, omitted fields are kept. (ex. {"require_registration": false})
+func NewConfigSetRequest(config map[string]interface{}) (*Request, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewRequest(FnConfigSet, string(data)), nil
+}
+
+// NewMigrateRequest _
+// 0 batch size means 'fetch_size' of the config.
+func NewMigrateRequest(batchSize int) *Request {
+	if batchSize <= 0 {
+		return NewRequest(FnMigrate)
+	}
+	return NewRequest(FnMigrate, strconv.Itoa(batchSize))
+}
+
+// NewMigrateStatusRequest _
+func NewMigrateStatusRequest() *Request {
+	return NewRequest(FnMigrateStatus)
+}
+
+// NewListRequest _
+// Empty ccids means all chaincodes, empty option means 'unsigned'.
+func NewListRequest(ccids []string, option, bookmark string) *Request {
+	if option == "" {
+		option = ListUnsigned
+	}
+	return NewRequest(FnList, joinCCIDs(ccids), option, bookmark)
+}
+
+// NewSearchRequest _
+func NewSearchRequest(selector map[string]interface{}, bookmark string) (*Request, error) {
+	data, err := json.Marshal(selector)
+	if err != nil {
+		return nil, err
+	}
+	return NewRequest(FnSearch, string(data), bookmark), nil
+}
+
+// NewSummaryRequest _
+// Empty ccids means all chaincodes.
+func NewSummaryRequest(ccids []string) *Request {
+	return NewRequest(FnSummary, joinCCIDs(ccids))
+}
+
+// NewVerRequest _
+func NewVerRequest() *Request {
+	return NewRequest(FnVer)
+}
+
+func joinCCIDs(ccids []string) string {
+	if len(ccids) == 0 {
+		return "*"
+	}
+	return strings.Join(ccids, ",")
+}
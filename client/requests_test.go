@@ -0,0 +1,55 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequestBuilders(t *testing.T) {
+	mustBuild := func(r *Request, err error) *Request {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	tests := []struct {
+		name     string
+		request  *Request
+		wantFn   string
+		wantArgs []string
+	}{
+		{"retry_execute", NewRetryExecuteRequest("c1"), FnRetryExecute, []string{"c1"}},
+		{"admin/cancel", NewAdminCancelRequest("c1", "fraud"), FnAdminCancel, []string{"c1", "fraud"}},
+		{"admin/freeze", NewAdminFreezeRequest("c1", "dispute"), FnAdminFreeze, []string{"c1", "dispute"}},
+		{"admin/unfreeze", NewAdminUnfreezeRequest("c1", "resolved"), FnAdminUnfreeze, []string{"c1", "resolved"}},
+		{"ccid/get", NewCCIDGetRequest("cc"), FnCCIDGet, []string{"cc"}},
+		{"ccid/list", NewCCIDListRequest("b1"), FnCCIDList, []string{"b1"}},
+		{"ccid/register defaults", mustBuild(NewCCIDRegisterRequest("cc", nil)), FnCCIDRegister, []string{"cc"}},
+		{"ccid/register limits", mustBuild(NewCCIDRegisterRequest("cc", &RegistrationLimits{MaxSigners: 10, CallbackFunctions: []string{"contract/execute"}})),
+			FnCCIDRegister, []string{"cc", `{"max_signers":10,"callback_functions":["contract/execute"]}`}},
+		{"ccid/update", mustBuild(NewCCIDUpdateRequest("cc", nil)), FnCCIDUpdate, []string{"cc", `{}`}},
+		{"ccid/suspend", NewCCIDSuspendRequest("cc", "abuse"), FnCCIDSuspend, []string{"cc", "abuse"}},
+		{"ccid/resume", NewCCIDResumeRequest("cc"), FnCCIDResume, []string{"cc"}},
+		{"config", NewConfigRequest(), FnConfig, []string{}},
+		{"config/set", mustBuild(NewConfigSetRequest(map[string]interface{}{"require_registration": false})), FnConfigSet, []string{`{"require_registration":false}`}},
+		{"migrate default", NewMigrateRequest(0), FnMigrate, []string{}},
+		{"migrate batch", NewMigrateRequest(100), FnMigrate, []string{"100"}},
+		{"migrate/status", NewMigrateStatusRequest(), FnMigrateStatus, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.request.Function != tt.wantFn {
+				t.Errorf("function = %q, want %q", tt.request.Function, tt.wantFn)
+			}
+			args := tt.request.Args
+			if nil == args {
+				args = []string{}
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %q, want %q", args, tt.wantArgs)
+			}
+		})
+	}
+}
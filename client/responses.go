@@ -0,0 +1,280 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// contract statuses
+const (
+	StatusPending     = "pending"
+	StatusExecuted    = "executed"
+	StatusCanceled    = "canceled"
+	StatusDisapproved = "disapproved"
+	StatusExpired     = "expired"
+)
+
+// Sign is the signer's action
+type Sign struct {
+	Signer              string     `json:"signer"`
+	ApprovedTime        *time.Time `json:"approved_time,omitempty"`
+	DisapprovedTime     *time.Time `json:"disapproved_time,omitempty"`
+	CancelRequestedTime *time.Time `json:"cancel_requested_time,omitempty"`
+	Comment             string     `json:"comment,omitempty"`
+	Signature           *Signature `json:"signature,omitempty"`
+}
+
+// CallbackTarget _
+type CallbackTarget struct {
+	Chaincode   string     `json:"chaincode"`
+	Channel     string     `json:"channel,omitempty"`
+	Execute     string     `json:"execute"`
+	Cancel      string     `json:"cancel"`
+	Approve     string     `json:"approve,omitempty"`
+	UpdatedTime *time.Time `json:"updated_time,omitempty"`
+}
+
+// CallbackResult _
+type CallbackResult struct {
+	Status  int32      `json:"status"`
+	Payload string     `json:"payload,omitempty"` // base64 encoded
+	Message string     `json:"message,omitempty"`
+	Time    *time.Time `json:"time"`
+}
+
+// DecodePayload returns the decoded payload of the callback
+func (r *CallbackResult) DecodePayload() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(r.Payload)
+}
+
+// CallbackResults is the response of 'callbacks'
+type CallbackResults struct {
+	ContractID string          `json:"contract_id"`
+	Execute    *CallbackResult `json:"execute,omitempty"`
+	Cancel     *CallbackResult `json:"cancel,omitempty"`
+}
+
+// AdminAction _
+type AdminAction struct {
+	Action string     `json:"action"`
+	Admin  string     `json:"admin"`
+	Reason string     `json:"reason"`
+	Time   *time.Time `json:"time"`
+}
+
+// Contract is the response of 'create', 'approve', 'get', etc. and records of 'list'
+type Contract struct {
+	ID                   string          `json:"@contract"`
+	Creator              string          `json:"creator"`
+	SignersCount         int             `json:"signers_count"`
+	ApprovedCount        int             `json:"approved_count,omitempty"` // 0 in 'approve' responses (not aggregated)
+	CCID                 string          `json:"ccid"`
+	Document             string          `json:"document"`
+	CallbackTarget       *CallbackTarget `json:"callback_target,omitempty"`
+	CancelPolicy         *CancelPolicy   `json:"cancel_policy,omitempty"`
+	ExecuteResult        *CallbackResult `json:"execute_result,omitempty"`
+	CancelResult         *CallbackResult `json:"cancel_result,omitempty"`
+	CreatedTime          *time.Time      `json:"created_time,omitempty"`
+	UpdatedTime          *time.Time      `json:"updated_time,omitempty"`
+	UpdatedBy            string          `json:"updated_by,omitempty"`
+	ExpiryTime           *time.Time      `json:"expiry_time,omitempty"`
+	ExecutedTime         *time.Time      `json:"executed_time,omitempty"`
+	CanceledTime         *time.Time      `json:"canceled_time,omitempty"`
+	Disapprover          string          `json:"disapprover,omitempty"`
+	FinishedTime         *time.Time      `json:"finished_time,omitempty"`
+	FailedTime           *time.Time      `json:"failed_time,omitempty"`
+	FailedReason         string          `json:"failed_reason,omitempty"`
+	FrozenTime           *time.Time      `json:"frozen_time,omitempty"`
+	AdminActions         []*AdminAction  `json:"admin_actions,omitempty"`
+	LastSigner           string          `json:"last_signer,omitempty"`
+	Callback             string          `json:"callback,omitempty"` // payload of the execute callback, 'execute' only
+	Sign                 *Sign           `json:"sign"`
+	CancelRequestedCount int             `json:"cancel_requested_count,omitempty"`
+	Status               string          `json:"status,omitempty"`
+	Signs                []*Sign         `json:"signs,omitempty"` // 'detail' only
+	Hash                 string          `json:"hash,omitempty"`  // 'detail' only
+}
+
+// PublicKey is the response of 'key/get' and 'key/register'
+type PublicKey struct {
+	KID            string     `json:"@public_key"`
+	Algorithm      string     `json:"algorithm"`
+	Key            string     `json:"key"` // base64 PKIX (DER)
+	RegisteredTime *time.Time `json:"registered_time,omitempty"`
+	UpdatedTime    *time.Time `json:"updated_time,omitempty"`
+}
+
+// ContractStatus is the response of 'contract/status' and records of 'search' by the chaincode
+type ContractStatus struct {
+	ContractID    string          `json:"contract_id"`
+	CCID          string          `json:"ccid"`
+	Status        string          `json:"status"`
+	SignersCount  int             `json:"signers_count"`
+	ApprovedCount int             `json:"approved_count"`
+	Signs         []*Sign         `json:"signs"`
+	ExecuteResult *CallbackResult `json:"execute_result,omitempty"`
+	CancelResult  *CallbackResult `json:"cancel_result,omitempty"`
+	ExpiryTime    *time.Time      `json:"expiry_time,omitempty"`
+	ExecutedTime  *time.Time      `json:"executed_time,omitempty"`
+	CanceledTime  *time.Time      `json:"canceled_time,omitempty"`
+	FailedTime    *time.Time      `json:"failed_time,omitempty"`
+	FailedReason  string          `json:"failed_reason,omitempty"`
+	FrozenTime    *time.Time      `json:"frozen_time,omitempty"`
+}
+
+// HistoryChange _
+type HistoryChange struct {
+	Record string      `json:"record"` // 'header' or signer's KID
+	Field  string      `json:"field"`
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new,omitempty"`
+}
+
+// HistoryEntry _
+type HistoryEntry struct {
+	TxID    string           `json:"tx_id"`
+	Time    *time.Time       `json:"time"`
+	Actor   string           `json:"actor,omitempty"`
+	Changes []*HistoryChange `json:"changes"`
+}
+
+// ContractHistory is the response of 'history'
+type ContractHistory struct {
+	ContractID string          `json:"contract_id"`
+	Entries    []*HistoryEntry `json:"entries"`
+}
+
+// Counts is the number of contracts per signer status
+type Counts struct {
+	Unsigned    int `json:"unsigned"`
+	Approved    int `json:"approved"`
+	Executed    int `json:"executed"`
+	Canceled    int `json:"canceled"`
+	Disapproved int `json:"disapproved"`
+	Expired     int `json:"expired"`
+}
+
+// CCIDCounts is counts of the chaincode
+type CCIDCounts struct {
+	CCID string `json:"ccid"`
+	Counts
+}
+
+// Summary is the response of 'summary'
+type Summary struct {
+	Signer string        `json:"signer"`
+	Counts               // total
+	CCIDs  []*CCIDCounts `json:"ccids"`
+}
+
+// QueryMeta is the metadata of the page
+type QueryMeta struct {
+	FetchedRecordsCount int32  `json:"fetched_records_count,omitempty"`
+	Bookmark            string `json:"bookmark,omitempty"`
+}
+
+// QueryResult is the page of 'list', 'search', etc.
+type QueryResult struct {
+	Meta    *QueryMeta        `json:"meta,omitempty"`
+	Records []json.RawMessage `json:"records"`
+}
+
+// Bookmark returns the bookmark of the next page
+func (r *QueryResult) Bookmark() string {
+	if nil == r.Meta {
+		return ""
+	}
+	return r.Meta.Bookmark
+}
+
+// Contracts decodes records as contracts
+func (r *QueryResult) Contracts() ([]*Contract, error) {
+	contracts := make([]*Contract, 0, len(r.Records))
+	for _, record := range r.Records {
+		contract := &Contract{}
+		if err := json.Unmarshal(record, contract); err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, contract)
+	}
+	return contracts, nil
+}
+
+// ContractStatuses decodes records as contract statuses ('search' by the chaincode)
+func (r *QueryResult) ContractStatuses() ([]*ContractStatus, error) {
+	statuses := make([]*ContractStatus, 0, len(r.Records))
+	for _, record := range r.Records {
+		status := &ContractStatus{}
+		if err := json.Unmarshal(record, status); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// DecodeContract _
+func DecodeContract(payload []byte) (*Contract, error) {
+	contract := &Contract{}
+	if err := json.Unmarshal(payload, contract); err != nil {
+		return nil, err
+	}
+	return contract, nil
+}
+
+// DecodeContractStatus _
+func DecodeContractStatus(payload []byte) (*ContractStatus, error) {
+	status := &ContractStatus{}
+	if err := json.Unmarshal(payload, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// DecodeCallbackResults _
+func DecodeCallbackResults(payload []byte) (*CallbackResults, error) {
+	results := &CallbackResults{}
+	if err := json.Unmarshal(payload, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DecodeContractHistory _
+func DecodeContractHistory(payload []byte) (*ContractHistory, error) {
+	history := &ContractHistory{}
+	if err := json.Unmarshal(payload, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// DecodePublicKey _
+func DecodePublicKey(payload []byte) (*PublicKey, error) {
+	key := &PublicKey{}
+	if err := json.Unmarshal(payload, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DecodeSummary _
+func DecodeSummary(payload []byte) (*Summary, error) {
+	summary := &Summary{}
+	if err := json.Unmarshal(payload, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// DecodeQueryResult _
+func DecodeQueryResult(payload []byte) (*QueryResult, error) {
+	result := &QueryResult{}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,220 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package client
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// signature algorithms
+const (
+	SignatureAlgorithmECDSA   = "ecdsa"
+	SignatureAlgorithmEd25519 = "ed25519"
+)
+
+// TransientSignature is the transient key of the signer's signature over the contract hash ('approve')
+const TransientSignature = "kiesnet-contract/signature"
+
+// WithSignature sets the signature over the contract hash to the transient
+func (r *Request) WithSignature(sig []byte) *Request {
+	if nil == r.Transient {
+		r.Transient = map[string][]byte{}
+	}
+	r.Transient[TransientSignature] = sig
+	return r
+}
+
+// ParsePublicKey parses the PEM or base64 encoded PKIX (DER) public key.
+// It returns the algorithm and DER bytes of the key.
+func ParsePublicKey(key string) (string, []byte, error) {
+	key = strings.TrimSpace(key)
+	var der []byte
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		der = block.Bytes
+	} else {
+		var err error
+		if der, err = base64.StdEncoding.DecodeString(key); err != nil {
+			return "", nil, errors.New("invalid public key encoding, expecting PEM or base64")
+		}
+	}
+	pub, err := parsePKIXPublicKey(der)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := pub.(ed25519.PublicKey); ok {
+		return SignatureAlgorithmEd25519, der, nil
+	}
+	return SignatureAlgorithmECDSA, der, nil
+}
+
+// oidEd25519 is the algorithm identifier of Ed25519 public keys (RFC 8410)
+var oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+
+// pkixPublicKey is the ASN.1 structure of PKIX public keys (SubjectPublicKeyInfo)
+type pkixPublicKey struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// parsePKIXPublicKey returns *ecdsa.PublicKey or ed25519.PublicKey.
+// x509 of Go before 1.13 can't parse Ed25519 keys, so they are parsed from the ASN.1 structure.
+func parsePKIXPublicKey(der []byte) (interface{}, error) {
+	spki := pkixPublicKey{}
+	if rest, err := asn1.Unmarshal(der, &spki); err != nil || len(rest) > 0 {
+		return nil, errors.New("invalid public key, expecting PKIX")
+	}
+	if spki.Algorithm.Algorithm.Equal(oidEd25519) {
+		if spki.PublicKey.BitLength != 8*ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(spki.PublicKey.Bytes), nil
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, errors.New("invalid public key, expecting PKIX")
+	}
+	if key, ok := pub.(*ecdsa.PublicKey); ok {
+		return key, nil
+	}
+	return nil, errors.New("unsupported public key, expecting ECDSA or Ed25519")
+}
+
+// VerifySignature verifies the signature over the hash by the PKIX (DER) public key.
+// ECDSA signatures are ASN.1 (r, s) over the hash, Ed25519 signatures sign the hash bytes as the message.
+func VerifySignature(der, hash, sig []byte) error {
+	pub, err := parsePKIXPublicKey(der)
+	if err != nil {
+		return err
+	}
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		rs := struct{ R, S *big.Int }{}
+		if rest, err := asn1.Unmarshal(sig, &rs); err != nil || len(rest) > 0 || nil == rs.R || nil == rs.S {
+			return errors.New("invalid ECDSA signature encoding")
+		}
+		if !ecdsa.Verify(key, hash, rs.R, rs.S) {
+			return errors.New("signature verification failed")
+		}
+	case ed25519.PublicKey:
+		if len(sig) != ed25519.SignatureSize || !ed25519.Verify(key, hash, sig) {
+			return errors.New("signature verification failed")
+		}
+	default:
+		return errors.New("unsupported public key, expecting ECDSA or Ed25519")
+	}
+	return nil
+}
+
+// Signature is the signer's signature over the contract hash, stored on the sign
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"` // base64 PKIX (DER)
+	Hash      string `json:"hash"`       // hex SHA-256 of the canonical contract
+	Value     string `json:"value"`      // base64
+}
+
+// Verify verifies the signature over the contract by the public key of it, independently of the chaincode.
+// The hash is recomputed from the contract ('detail', all signers are required) and compared with the signed hash.
+func (s *Signature) Verify(contract *Contract) error {
+	digest, err := contract.Digest()
+	if err != nil {
+		return err
+	}
+	hash, err := digest.Hash()
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(hash) != s.Hash {
+		return errors.New("the signed hash isn't the hash of the contract")
+	}
+	der, err := base64.StdEncoding.DecodeString(s.PublicKey)
+	if err != nil {
+		return errors.New("invalid public key encoding")
+	}
+	sig, err := base64.StdEncoding.DecodeString(s.Value)
+	if err != nil {
+		return errors.New("invalid signature encoding")
+	}
+	return VerifySignature(der, hash, sig)
+}
+
+// DigestTimeFormat is the time format of the contract digest, fixed 9 digits of nanoseconds in UTC.
+// It's the format of times stored by the chaincode. (txtime.RFC3339NanoFixed)
+const DigestTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// ContractDigest is the canonical form of the contract signed by signers.
+// It has only immutable fields of the contract, so the hash doesn't change after the creation.
+// The chaincode hashes the same digest, so the JSON form must not be changed.
+type ContractDigest struct {
+	ContractID  string   `json:"contract_id"`
+	CCID        string   `json:"ccid"`
+	Creator     string   `json:"creator"`
+	Document    string   `json:"document"`
+	Signers     []string `json:"signers"`                // sorted
+	CreatedTime string   `json:"created_time,omitempty"` // DigestTimeFormat
+	ExpiryTime  string   `json:"expiry_time,omitempty"`  // DigestTimeFormat
+}
+
+// NewContractDigest _
+// Signers are sorted, and times are formatted by DigestTimeFormat.
+func NewContractDigest(id, ccid, creator, document string, signers []string, created, expiry *time.Time) *ContractDigest {
+	sorted := append([]string{}, signers...)
+	sort.Strings(sorted)
+	return &ContractDigest{
+		ContractID:  id,
+		CCID:        ccid,
+		Creator:     creator,
+		Document:    document,
+		Signers:     sorted,
+		CreatedTime: formatDigestTime(created),
+		ExpiryTime:  formatDigestTime(expiry),
+	}
+}
+
+func formatDigestTime(t *time.Time) string {
+	if nil == t {
+		return ""
+	}
+	return t.UTC().Format(DigestTimeFormat)
+}
+
+// Hash returns SHA-256 of the compact JSON (without HTML escaping) of the digest
+func (d *ContractDigest) Hash() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}))
+	return h[:], nil
+}
+
+// Digest returns the digest of the contract.
+// Signers are known by signs of 'detail', so the contract must have all signs.
+func (c *Contract) Digest() (*ContractDigest, error) {
+	if len(c.Signs) != c.SignersCount {
+		return nil, errors.New("signs of all signers are required, expecting the contract of 'detail'")
+	}
+	signers := make([]string, 0, len(c.Signs))
+	for _, sign := range c.Signs {
+		signers = append(signers, sign.Signer)
+	}
+	return NewContractDigest(c.ID, c.CCID, c.Creator, c.Document, signers, c.CreatedTime, c.ExpiryTime), nil
+}
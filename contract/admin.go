@@ -0,0 +1,55 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+	"github.com/pkg/errors"
+)
+
+// admin actions
+const (
+	AdminActionCancel   = "cancel"
+	AdminActionFreeze   = "freeze"
+	AdminActionUnfreeze = "unfreeze"
+)
+
+// AdminAction is the record of the administrator's action on the contract
+type AdminAction struct {
+	Action string       `json:"action"`
+	Admin  string       `json:"admin"`
+	Reason string       `json:"reason"`
+	Time   *txtime.Time `json:"time"`
+}
+
+// GetAdmin authenticates the invoker as an administrator, and returns the name of the administrator.
+// The administrator is identified by the KID (admins) or the MSP ID (admin_msps) of the config.
+// By the MSP ID, the name is 'MSPID/CN' of the certificate.
+func GetAdmin(stub shim.ChaincodeStubInterface) (string, error) {
+	cfg, err := NewConfigStub(stub).GetConfig()
+	if err != nil {
+		return "", err
+	}
+	if len(cfg.Admins) > 0 {
+		if kid, err := NewIdentityProvider(cfg).GetID(stub, true); err == nil && cfg.IsAdminKID(kid) {
+			return kid, nil
+		}
+	}
+	if len(cfg.AdminMSPs) > 0 {
+		msp, err := cid.GetMSPID(stub)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get the MSP ID")
+		}
+		if cfg.IsAdminMSP(msp) {
+			cert, err := cid.GetX509Certificate(stub)
+			if err != nil || nil == cert {
+				return "", NewContractError(client.ErrorCodeAuthenticationFailed, "failed to get the certificate")
+			}
+			return msp + "/" + cert.Subject.CommonName, nil
+		}
+	}
+	return "", NewContractError(client.ErrorCodeNotAdministrator, "invalid access, not an administrator")
+}
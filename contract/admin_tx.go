@@ -0,0 +1,134 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// params[0] : contract ID
+// params[1] : reason
+func adminCancel(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 2 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 2")
+	}
+
+	// authentication
+	admin, err := GetAdmin(stub)
+	if err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	id := params[0]
+	reason := params[1]
+	if "" == reason {
+		return responseCode(client.ErrorCodeInvalidParameters, "reason is required")
+	}
+
+	ts, err := txtime.GetTime(stub)
+	if err != nil {
+		return responseError(err, "failed to cancel the contract")
+	}
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContractByID(id)
+	if err != nil {
+		return responseError(err, "failed to cancel the contract")
+	}
+	// validate
+	if contract.FinishedTime != nil && ts.Cmp(contract.FinishedTime) >= 0 { // ts >= finished_time => expired
+		return responseCode(client.ErrorCodeAlreadyFinished, "already finished contract")
+	}
+
+	// cancel contract, the failed callback can't veto the emergency cancel (the result is recorded)
+	contract.CancelResult, _ = invokeCancelContract(stub, contract)
+	contract.AddAdminAction(AdminActionCancel, admin, reason, ts)
+	if contract, err = cb.CancelContract(contract, admin); err != nil {
+		return responseError(err, "failed to cancel the contract")
+	}
+
+	return response(contract)
+}
+
+// params[0] : contract ID
+// params[1] : reason
+func adminFreeze(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 2 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 2")
+	}
+
+	// authentication
+	admin, err := GetAdmin(stub)
+	if err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	id := params[0]
+	reason := params[1]
+	if "" == reason {
+		return responseCode(client.ErrorCodeInvalidParameters, "reason is required")
+	}
+
+	ts, err := txtime.GetTime(stub)
+	if err != nil {
+		return responseError(err, "failed to freeze the contract")
+	}
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContractByID(id)
+	if err != nil {
+		return responseError(err, "failed to freeze the contract")
+	}
+	// validate
+	if contract.FinishedTime != nil && ts.Cmp(contract.FinishedTime) >= 0 { // ts >= finished_time => expired
+		return responseCode(client.ErrorCodeAlreadyFinished, "already finished contract")
+	}
+	if contract.FrozenTime != nil {
+		return responseCode(client.ErrorCodeAlreadyFrozen, "already frozen contract")
+	}
+
+	if contract, err = cb.FreezeContract(contract, admin, reason); err != nil {
+		return responseError(err, "failed to freeze the contract")
+	}
+
+	return response(contract)
+}
+
+// params[0] : contract ID
+// params[1] : reason
+func adminUnfreeze(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 2 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 2")
+	}
+
+	// authentication
+	admin, err := GetAdmin(stub)
+	if err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	id := params[0]
+	reason := params[1]
+	if "" == reason {
+		return responseCode(client.ErrorCodeInvalidParameters, "reason is required")
+	}
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContractByID(id)
+	if err != nil {
+		return responseError(err, "failed to unfreeze the contract")
+	}
+	// validate
+	if nil == contract.FrozenTime {
+		return responseCode(client.ErrorCodeNotFrozen, "not frozen contract")
+	}
+
+	if contract, err = cb.UnfreezeContract(contract, admin, reason); err != nil {
+		return responseError(err, "failed to unfreeze the contract")
+	}
+
+	return response(contract)
+}
@@ -0,0 +1,90 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+)
+
+// default callback functions
+const (
+	DefaultExecuteCallback = "contract/execute"
+	DefaultCancelCallback  = "contract/cancel"
+)
+
+// CallbackTarget represents the chaincode and functions invoked as callbacks.
+// Cross-channel invocations are read-only, so writes of callbacks on another channel aren't committed.
+type CallbackTarget struct {
+	Chaincode   string       `json:"chaincode"`
+	Channel     string       `json:"channel,omitempty"` // empty means the same channel
+	Execute     string       `json:"execute"`
+	Cancel      string       `json:"cancel"`
+	Approve     string       `json:"approve,omitempty"` // empty means disabled (opt-in)
+	UpdatedTime *txtime.Time `json:"updated_time,omitempty"`
+}
+
+// NewCallbackTarget creates the callback target. Empty values are replaced with defaults.
+// The approve callback is optional, so empty approve means disabled.
+func NewCallbackTarget(ccid, chaincode, channel, execute, cancel, approve string) *CallbackTarget {
+	if chaincode == "" {
+		chaincode = ccid
+	}
+	if execute == "" {
+		execute = DefaultExecuteCallback
+	}
+	if cancel == "" {
+		cancel = DefaultCancelCallback
+	}
+	return &CallbackTarget{
+		Chaincode: chaincode,
+		Channel:   channel,
+		Execute:   execute,
+		Cancel:    cancel,
+		Approve:   approve,
+	}
+}
+
+// MarshalPayload _
+func (t *CallbackTarget) MarshalPayload() ([]byte, error) {
+	return json.Marshal(t)
+}
+
+// CallbackAllowance is the list of CCIDs allowed to use the chaincode as their callback target.
+// Other chaincodes must opt in, so they aren't invoked with documents of the CCID without consent.
+type CallbackAllowance struct {
+	DOCTYPEID   string       `json:"@callback_allowance"` // chaincode name
+	CCIDs       []string     `json:"ccids"`
+	UpdatedTime *txtime.Time `json:"updated_time,omitempty"`
+}
+
+// IsAllowed _
+func (a *CallbackAllowance) IsAllowed(ccid string) bool {
+	return contains(a.CCIDs, ccid)
+}
+
+// MarshalPayload _
+func (a *CallbackAllowance) MarshalPayload() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// CallbackResult represents the response of the callback invocation
+type CallbackResult struct {
+	Status  int32        `json:"status"`
+	Payload string       `json:"payload,omitempty"` // base64 encoded
+	Message string       `json:"message,omitempty"`
+	Time    *txtime.Time `json:"time"`
+}
+
+// CallbackResults _
+type CallbackResults struct {
+	ContractID string          `json:"contract_id"`
+	Execute    *CallbackResult `json:"execute,omitempty"`
+	Cancel     *CallbackResult `json:"cancel,omitempty"`
+}
+
+// MarshalPayload _
+func (r *CallbackResults) MarshalPayload() ([]byte, error) {
+	return json.Marshal(r)
+}
@@ -0,0 +1,108 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/key-inside/kiesnet-ccpkg/ccid"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// params[0] : comma separated CCIDs allowed to use the invoker chaincode as their callback target (empty means none)
+func callbackAllow(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	cfg, err := NewConfigStub(stub).GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+	if cfg.IsBlockedCCID(ccid) {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	allowance := &CallbackAllowance{DOCTYPEID: ccid, CCIDs: splitList(params[0])}
+	if err = NewContractStub(stub).PutCallbackAllowance(allowance); err != nil {
+		return responseError(err, "failed to allow callbacks")
+	}
+
+	return response(allowance)
+}
+
+// params[0] : ccid
+func callbackGet(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	ccid := params[0]
+
+	cb := NewContractStub(stub)
+	target, err := cb.GetCallbackTarget(ccid)
+	if err != nil {
+		return responseError(err, "failed to get the callback target")
+	}
+	if nil == target {
+		target = NewCallbackTarget(ccid, "", "", "", "", "")
+	}
+
+	return response(target)
+}
+
+// params[0] : chaincode name (empty means the invoker chaincode, others must allow the invoker by 'callback/allow')
+// params[1] : channel name (empty means the same channel, cross-channel invocations are read-only)
+// params[2] : execute function name (default 'contract/execute')
+// params[3] : cancel function name (default 'contract/cancel')
+// params[4] : approve function name (optional, ex. 'contract/approve')
+func callbackSet(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	cfg, err := NewConfigStub(stub).GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+	if cfg.IsBlockedCCID(ccid) {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	if len(params) < 4 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 4+")
+	}
+
+	approve := ""
+	if len(params) > 4 {
+		approve = params[4]
+	}
+
+	target := NewCallbackTarget(ccid, params[0], params[1], params[2], params[3], approve)
+
+	reg, err := NewRegistrationStub(stub).GetCreatorRegistration(ccid, cfg)
+	if err != nil {
+		return responseError(err, "failed to set the callback target")
+	}
+	if reg != nil {
+		if err = reg.AssertCallbackTarget(target); err != nil {
+			return responseError(err, "failed to set the callback target")
+		}
+	}
+
+	cb := NewContractStub(stub)
+	if err = cb.AssertCallbackAllowed(ccid, target); err != nil {
+		return responseError(err, "failed to set the callback target")
+	}
+	if err = cb.PutCallbackTarget(ccid, target); err != nil {
+		return responseError(err, "failed to set the callback target")
+	}
+
+	return response(target)
+}
@@ -0,0 +1,41 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/pkg/errors"
+)
+
+// cancel policy types
+const (
+	CancelPolicyCreator = "creator" // only the creator, directly or through the CCID
+	CancelPolicySigner  = "signer"  // any signer through the CCID (default)
+	CancelPolicyQuorum  = "quorum"  // k-of-n signers, directly or through the CCID
+	CancelPolicyCCID    = "ccid"    // only the CCID
+)
+
+// CancelPolicy represents who can cancel the contract
+type CancelPolicy struct {
+	Type      string `json:"type"`
+	Threshold int    `json:"threshold,omitempty"` // k of k-of-n (quorum only)
+}
+
+// DefaultCancelPolicy _
+var DefaultCancelPolicy = &CancelPolicy{Type: CancelPolicySigner}
+
+// Validate validates the policy with the number of signers
+func (p *CancelPolicy) Validate(scount int) error {
+	switch p.Type {
+	case CancelPolicyCreator, CancelPolicySigner, CancelPolicyCCID:
+		if p.Threshold != 0 {
+			return errors.New("threshold is only for the quorum cancel policy")
+		}
+	case CancelPolicyQuorum:
+		if p.Threshold < 1 || p.Threshold > scount {
+			return errors.Errorf("invalid cancel threshold, expecting 1 ~ %d", scount)
+		}
+	default:
+		return errors.Errorf("unknown cancel policy: [%s]", p.Type)
+	}
+	return nil
+}
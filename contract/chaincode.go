@@ -0,0 +1,178 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+// Package contract is the chaincode of kiesnet-contract.
+// The main package starts it, and other chaincodes can import it to test with it. (see testkit)
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/payprotocol/kiesnet-contract/client"
+	"github.com/pkg/errors"
+)
+
+var logger = shim.NewLogger("kiesnet-contract")
+
+// Chaincode _
+type Chaincode struct {
+}
+
+// New returns the chaincode of kiesnet-contract
+func New() *Chaincode {
+	return &Chaincode{}
+}
+
+// Init implements shim.Chaincode interface.
+// params[0] : config JSON object, or state database 1 of [couchdb, leveldb] (optional, default couchdb)
+// params[1] : MSP IDs of administrators, comma separated (optional, without the config JSON)
+// params[2] : KIDs of administrators, comma separated (optional, without the config JSON)
+// If params are empty (ex. upgrade), the current configuration is kept.
+// A new deployment (no state) starts at the latest schema version.
+func (cc *Chaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	if err := NewMigrationStub(stub).InitMigration(); err != nil {
+		return responseError(err, "failed to init the schema version")
+	}
+
+	_, params := stub.GetFunctionAndParameters()
+	if len(params) == 0 {
+		return shim.Success(nil)
+	}
+
+	sb := NewConfigStub(stub)
+	cfg, err := sb.GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+	if strings.HasPrefix(strings.TrimSpace(params[0]), "{") {
+		if err = cfg.Merge([]byte(params[0])); err != nil {
+			return responseCode(client.ErrorCodeInvalidConfig, err.Error())
+		}
+	} else {
+		cfg.StateDB = params[0]
+		if len(params) > 1 {
+			cfg.AdminMSPs = splitList(params[1])
+		}
+		if len(params) > 2 {
+			cfg.Admins = splitList(params[2])
+		}
+		if err = cfg.Validate(); err != nil {
+			return responseCode(client.ErrorCodeInvalidConfig, err.Error())
+		}
+	}
+	if err = sb.PutConfig(cfg); err != nil {
+		return responseError(err, "failed to put the config")
+	}
+
+	return shim.Success(nil)
+}
+
+// Invoke implements shim.Chaincode interface.
+func (cc *Chaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	fn, params := stub.GetFunctionAndParameters()
+	if txFn := routes[fn]; txFn != nil {
+		return txFn(stub, params)
+	}
+	return responseCode(client.ErrorCodeUnknownFunction, "unknown function: ["+fn+"]")
+}
+
+// TxFunc _
+type TxFunc func(shim.ChaincodeStubInterface, []string) peer.Response
+
+// routes is the map of invoke functions, function names are shared with the client package
+var routes = map[string]TxFunc{
+	client.FnAdminCancel:    adminCancel,
+	client.FnAdminFreeze:    adminFreeze,
+	client.FnAdminUnfreeze:  adminUnfreeze,
+	client.FnApprove:        contractApprove,
+	client.FnCallbackAllow:  callbackAllow,
+	client.FnCallbackGet:    callbackGet,
+	client.FnCallbackSet:    callbackSet,
+	client.FnCallbacks:      contractCallbacks,
+	client.FnCancel:         contractCancel,
+	client.FnConfig:         configGet,
+	client.FnConfigSet:      configSet,
+	client.FnCCIDGet:        registrationGet,
+	client.FnCCIDList:       registrationList,
+	client.FnCCIDRegister:   registrationRegister,
+	client.FnCCIDResume:     registrationResume,
+	client.FnCCIDSuspend:    registrationSuspend,
+	client.FnCCIDUpdate:     registrationUpdate,
+	client.FnContractStatus: contractStatus,
+	client.FnCreate:         contractCreate,
+	client.FnDetail:         contractDetail,
+	client.FnDisapprove:     contractDisapprove,
+	client.FnExecute:        contractExecute,
+	client.FnGet:            contractGet,
+	client.FnHistory:        contractHistory,
+	client.FnKeyGet:         publicKeyGet,
+	client.FnKeyRegister:    publicKeyRegister,
+	client.FnList:           contractList,
+	client.FnMigrate:        migrate,
+	client.FnMigrateStatus:  migrateStatus,
+	client.FnRetryExecute:   contractRetryExecute,
+	client.FnSearch:         contractSearch,
+	client.FnSummary:        contractSummary,
+	client.FnVer:            ver,
+}
+
+// splitList splits the comma separated list, empty items are removed
+func splitList(s string) []string {
+	list := []string{}
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+func ver(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	m, err := NewMigrationStub(stub).GetMigration()
+	if err != nil {
+		return responseError(err, "failed to get the schema version")
+	}
+	return shim.Success([]byte(fmt.Sprintf("Kiesnet Contract v%s (schema v%d) created by Key Inside Co., Ltd.", Version, m.SchemaVersion)))
+}
+
+func response(payload Payload) peer.Response {
+	data, err := payload.MarshalPayload()
+	if err != nil {
+		logger.Debug(err.Error())
+		return responseCode(client.ErrorCodeInternal, "failed to marshal payload")
+	}
+	return shim.Success(data)
+}
+
+// If 'err' is ResponsibleError, it will add err's message to the 'msg'.
+// If 'err' is CodedError, its code is the code of the response, or it's INTERNAL_ERROR.
+func responseError(err error, msg string) peer.Response {
+	code := client.ErrorCodeInternal
+	if nil != err {
+		logger.Debug(err.Error())
+		cause := errors.Cause(err)
+		if _, ok := cause.(ResponsibleError); ok {
+			if len(msg) > 0 {
+				msg = msg + "|" + err.Error()
+			} else {
+				msg = err.Error()
+			}
+		}
+		if ce, ok := cause.(CodedError); ok {
+			code = ce.Code()
+		}
+	}
+	return responseCode(code, msg)
+}
+
+// responseCode returns the error response, its message is the JSON body of the code and the message.
+func responseCode(code client.ErrorCode, msg string) peer.Response {
+	data, err := json.Marshal(&client.Error{Code: code, Message: msg})
+	if err != nil {
+		return shim.Error(msg)
+	}
+	return shim.Error(string(data))
+}
@@ -0,0 +1,137 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// state databases
+const (
+	StateDBCouchDB = "couchdb"
+	StateDBLevelDB = "leveldb"
+)
+
+// identity providers
+const (
+	IdentityProviderKID = "kiesnet-id" // kiesnet-id chaincode (KID)
+	IdentityProviderCID = "cid"        // Fabric client identity (X.509 certificate)
+)
+
+// Config is the chaincode configuration stored in the state
+type Config struct {
+	DOCTYPEID     string   `json:"@config"`
+	StateDB       string   `json:"state_db"`             // couchdb or leveldb
+	AdminMSPs     []string `json:"admin_msps,omitempty"` // MSP IDs of administrators
+	Admins        []string `json:"admins,omitempty"`     // KIDs of administrators
+	DefaultExpiry int64    `json:"default_expiry"`       // seconds
+	MinExpiry     int64    `json:"min_expiry"`           // seconds, less expiry is replaced by the default
+	MaxSigners    int      `json:"max_signers"`          // including the creator
+	FetchSize     int32    `json:"fetch_size"`           // page size of lists
+	BlockedCCIDs  []string `json:"blocked_ccids"`        // chaincodes can't create contracts
+	// only registered chaincodes can create contracts (ccid/register), false opts out explicitly
+	RequireRegistration bool `json:"require_registration"`
+	// identity of invokers (signers), kiesnet-id or cid (init only)
+	IdentityProvider string `json:"identity_provider"`
+	// attribute of the certificate used as the ID 'MSPID/value', empty means 'MSPID/CN' (cid only, init only)
+	IdentityAttribute string `json:"identity_attribute,omitempty"`
+}
+
+// NewConfig returns the default configuration
+func NewConfig() *Config {
+	return &Config{
+		DOCTYPEID:           "config",
+		StateDB:             StateDBCouchDB,
+		DefaultExpiry:       15 * 24 * 60 * 60, // 15 days
+		MinExpiry:           600,               // 10 minutes
+		MaxSigners:          128,
+		FetchSize:           20,
+		BlockedCCIDs:        []string{"kiesnet-contract", "kiesnet-cc-contract"},
+		RequireRegistration: true,
+		IdentityProvider:    IdentityProviderKID,
+	}
+}
+
+// Merge overwrites fields of the config with fields of the JSON object, and validates it.
+// Omitted fields are kept.
+func (c *Config) Merge(data []byte) error {
+	if err := json.Unmarshal(data, c); err != nil {
+		return errors.Wrap(err, "failed to unmarshal the config")
+	}
+	c.DOCTYPEID = "config"
+	return c.Validate()
+}
+
+// Validate _
+func (c *Config) Validate() error {
+	switch c.StateDB {
+	case StateDBCouchDB, StateDBLevelDB:
+	default:
+		return errors.Errorf("unknown state database: [%s]", c.StateDB)
+	}
+	if c.MinExpiry < 0 || c.DefaultExpiry < c.MinExpiry {
+		return errors.New("invalid expiry, expecting 0 <= min_expiry <= default_expiry")
+	}
+	if c.MaxSigners < 2 {
+		return errors.New("invalid max_signers, expecting 2+")
+	}
+	if c.FetchSize < 1 {
+		return errors.New("invalid fetch_size, expecting 1+")
+	}
+	switch c.IdentityProvider {
+	case IdentityProviderKID:
+		if c.IdentityAttribute != "" {
+			return errors.New("identity_attribute is only for the cid identity provider")
+		}
+	case IdentityProviderCID:
+	default:
+		return errors.Errorf("unknown identity provider: [%s]", c.IdentityProvider)
+	}
+	return nil
+}
+
+// UseRichQuery reports whether the state database supports rich queries
+func (c *Config) UseRichQuery() bool {
+	return c.StateDB != StateDBLevelDB
+}
+
+// IsAdminMSP _
+func (c *Config) IsAdminMSP(msp string) bool {
+	return contains(c.AdminMSPs, msp)
+}
+
+// IsAdminKID _
+func (c *Config) IsAdminKID(kid string) bool {
+	return contains(c.Admins, kid)
+}
+
+// IsBlockedCCID reports whether the chaincode can't create contracts and set callbacks.
+// The contract chaincode itself is always blocked, regardless of the list.
+func (c *Config) IsBlockedCCID(ccid string) bool {
+	return isDirect(ccid) || contains(c.BlockedCCIDs, ccid)
+}
+
+// names of the contract chaincode itself (not editable by the config)
+var selfCCIDs = []string{"kiesnet-contract", "kiesnet-cc-contract"}
+
+// isDirect reports whether the invoker chaincode is the contract chaincode itself,
+// it means the function is invoked directly by the user, not by another chaincode.
+func isDirect(ccid string) bool {
+	return contains(selfCCIDs, ccid)
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalPayload _
+func (c *Config) MarshalPayload() ([]byte, error) {
+	return json.Marshal(c)
+}
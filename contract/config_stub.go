@@ -0,0 +1,50 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/pkg/errors"
+)
+
+// ConfigKey is the state key of the configuration
+const ConfigKey = "CFG"
+
+// ConfigStub _
+type ConfigStub struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewConfigStub _
+func NewConfigStub(stub shim.ChaincodeStubInterface) *ConfigStub {
+	return &ConfigStub{stub}
+}
+
+// GetConfig returns the configuration, or the default configuration if it's not stored
+func (sb *ConfigStub) GetConfig() (*Config, error) {
+	data, err := sb.stub.GetState(ConfigKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the config state")
+	}
+	cfg := NewConfig()
+	if data != nil {
+		if err = json.Unmarshal(data, cfg); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal the config")
+		}
+	}
+	return cfg, nil
+}
+
+// PutConfig _
+func (sb *ConfigStub) PutConfig(cfg *Config) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the config")
+	}
+	if err = sb.stub.PutState(ConfigKey, data); err != nil {
+		return errors.Wrap(err, "failed to put the config state")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+func configGet(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	cfg, err := NewConfigStub(stub).GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+
+	return response(cfg)
+}
+
+// params[0] : config JSON object (omitted fields are kept)
+// identity_provider and identity_attribute are init-only, they can't be changed.
+func configSet(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	// authentication
+	if _, err := GetAdmin(stub); err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	sb := NewConfigStub(stub)
+	cfg, err := sb.GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+	provider, attribute := cfg.IdentityProvider, cfg.IdentityAttribute
+	if err = cfg.Merge([]byte(params[0])); err != nil {
+		return responseCode(client.ErrorCodeInvalidConfig, err.Error())
+	}
+	if cfg.IdentityProvider != provider || cfg.IdentityAttribute != attribute {
+		return responseCode(client.ErrorCodeInvalidConfig, "identity_provider and identity_attribute can be set only by init")
+	}
+	if err = sb.PutConfig(cfg); err != nil {
+		return responseError(err, "failed to put the config")
+	}
+
+	return response(cfg)
+}
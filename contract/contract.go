@@ -0,0 +1,221 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// ContractHeader is the signer independent part of the contract.
+// It's stored once per contract.
+type ContractHeader struct {
+	Creator        string          `json:"creator"`
+	SignersCount   int             `json:"signers_count"`
+	ApprovedCount  int             `json:"approved_count,omitempty"` // aggregated on reads, omitted by 'approve'
+	CCID           string          `json:"ccid"`
+	Document       string          `json:"document"`
+	CallbackTarget *CallbackTarget `json:"callback_target,omitempty"`
+	CancelPolicy   *CancelPolicy   `json:"cancel_policy,omitempty"`
+	ExecuteResult  *CallbackResult `json:"execute_result,omitempty"`
+	CancelResult   *CallbackResult `json:"cancel_result,omitempty"`
+	CreatedTime    *txtime.Time    `json:"created_time,omitempty"`
+	UpdatedTime    *txtime.Time    `json:"updated_time,omitempty"`
+	UpdatedBy      string          `json:"updated_by,omitempty"`
+	ExpiryTime     *txtime.Time    `json:"expiry_time,omitempty"`
+	ExecutedTime   *txtime.Time    `json:"executed_time,omitempty"`
+	CanceledTime   *txtime.Time    `json:"canceled_time,omitempty"`
+	Disapprover    string          `json:"disapprover,omitempty"` // canceled by the disapproval
+	FinishedTime   *txtime.Time    `json:"finished_time,omitempty"`
+	FailedTime     *txtime.Time    `json:"failed_time,omitempty"`
+	FailedReason   string          `json:"failed_reason,omitempty"`
+	FrozenTime     *txtime.Time    `json:"frozen_time,omitempty"`
+	AdminActions   []*AdminAction  `json:"admin_actions,omitempty"`
+	LastSigner     string          `json:"last_signer,omitempty"`
+	Counted        bool            `json:"counted,omitempty"` // counted by counters (summary)
+}
+
+// contract statuses (computed)
+const (
+	ContractStatusPending = "pending"
+	ContractStatusExpired = "expired"
+	// executed, canceled, disapproved are same as event statuses
+)
+
+// ContractHeaderDoc is the state document of the contract header
+type ContractHeaderDoc struct {
+	DOCTYPEID      string          `json:"@contract_header"`
+	Signers        []string        `json:"signers"`
+	DocumentFields json.RawMessage `json:"document_fields,omitempty"` // structured document for 'search'
+	ContractHeader
+}
+
+// Contract represents the contract, assembled from the header and the signer's sign record.
+// (legacy contracts are stored as a whole for each signer)
+type Contract struct {
+	DOCTYPEID string `json:"@contract"`
+	ContractHeader
+	Callback             string       `json:"callback,omitempty"`
+	Sign                 *Sign        `json:"sign"`
+	CancelRequestedCount int          `json:"cancel_requested_count,omitempty"` // aggregated, not stored
+	Status               string       `json:"status,omitempty"`                 // computed, not stored
+	signers              []string     // all signers, not marshaled
+	legacy               bool         // stored by the legacy layout (simple keys)
+	ts                   *txtime.Time // time of the status
+}
+
+// AssertSignable _
+func (c *Contract) AssertSignable(t *txtime.Time) error {
+	if err := c.AssertExecutable(t); err != nil {
+		return err
+	}
+	if c.Sign.ApprovedTime != nil {
+		return NewContractError(client.ErrorCodeAlreadyApproved, "already approved")
+	}
+	if c.Sign.DisapprovedTime != nil {
+		return NewContractError(client.ErrorCodeAlreadyDisapproved, "already dispproved")
+	}
+	return nil
+}
+
+// AssertExecutable asserts that the contract is neither finished (executed, canceled or expired) nor frozen
+func (c *Contract) AssertExecutable(t *txtime.Time) error {
+	if c.ExecutedTime != nil {
+		return NewContractError(client.ErrorCodeAlreadyExecuted, "already executed")
+	}
+	if c.CanceledTime != nil {
+		return NewContractError(client.ErrorCodeAlreadyCanceled, "already canceled")
+	}
+	if c.ExpiryTime != nil && t != nil && t.Cmp(c.ExpiryTime) >= 0 {
+		return NewContractError(client.ErrorCodeExpired, "already expired")
+	}
+	if c.FrozenTime != nil {
+		return NewContractError(client.ErrorCodeFrozen, "frozen by the administrator")
+	}
+	return nil
+}
+
+// GetCallbackTarget returns the callback target of the contract.
+// If it's not set, the default target (CCID) is returned.
+func (c *Contract) GetCallbackTarget() *CallbackTarget {
+	if c.CallbackTarget != nil {
+		return c.CallbackTarget
+	}
+	return NewCallbackTarget(c.CCID, "", "", "", "", "")
+}
+
+// GetStatus returns the status of the contract at the time
+// 1 of [pending, executed, canceled, disapproved, expired]
+func (c *Contract) GetStatus(t *txtime.Time) string {
+	if c.ExecutedTime != nil {
+		return ContractStatusExecuted
+	}
+	if c.CanceledTime != nil {
+		if c.Disapprover != "" {
+			return ContractStatusDisapproved
+		}
+		return ContractStatusCanceled
+	}
+	if c.ExpiryTime != nil && t != nil && t.Cmp(c.ExpiryTime) >= 0 {
+		return ContractStatusExpired
+	}
+	return ContractStatusPending
+}
+
+// GetCancelPolicy returns the cancel policy of the contract.
+// If it's not set, the default policy (signer) is returned.
+func (c *Contract) GetCancelPolicy() *CancelPolicy {
+	if c.CancelPolicy != nil {
+		return c.CancelPolicy
+	}
+	return DefaultCancelPolicy
+}
+
+// AddAdminAction records the administrator's action
+func (c *Contract) AddAdminAction(action, admin, reason string, t *txtime.Time) {
+	c.AdminActions = append(c.AdminActions, &AdminAction{
+		Action: action,
+		Admin:  admin,
+		Reason: reason,
+		Time:   t,
+	})
+}
+
+// NewSignRecord creates the sign record of the signer with index fields of the contract
+func (c *Contract) NewSignRecord(sign *Sign) *SignRecord {
+	return &SignRecord{
+		DOCTYPEID:    c.DOCTYPEID,
+		CCID:         c.CCID,
+		CreatedTime:  c.CreatedTime,
+		ExpiryTime:   c.ExpiryTime,
+		ExecutedTime: c.ExecutedTime,
+		CanceledTime: c.CanceledTime,
+		FinishedTime: c.FinishedTime,
+		Disapprover:  c.Disapprover,
+		Sign:         sign,
+	}
+}
+
+// MarshalPayload _
+func (c *Contract) MarshalPayload() ([]byte, error) {
+	c.Status = c.GetStatus(c.ts)
+	return json.Marshal(c)
+}
+
+// ContractDetail is the contract with all signers' signs
+type ContractDetail struct {
+	*Contract
+	Signs []*Sign `json:"signs"` // sorted by signers
+	Hash  string  `json:"hash"`  // hex SHA-256 of the contract digest, signed by signatures
+}
+
+// MarshalPayload _
+func (d *ContractDetail) MarshalPayload() ([]byte, error) {
+	d.Status = d.GetStatus(d.ts)
+	return json.Marshal(d)
+}
+
+// ContractStatusResult is the status of the contract for the chaincode (CCID) created it
+type ContractStatusResult struct {
+	ContractID    string          `json:"contract_id"`
+	CCID          string          `json:"ccid"`
+	Status        string          `json:"status"`
+	SignersCount  int             `json:"signers_count"`
+	ApprovedCount int             `json:"approved_count"`
+	Signs         []*Sign         `json:"signs"` // sorted by signers
+	ExecuteResult *CallbackResult `json:"execute_result,omitempty"`
+	CancelResult  *CallbackResult `json:"cancel_result,omitempty"`
+	ExpiryTime    *txtime.Time    `json:"expiry_time,omitempty"`
+	ExecutedTime  *txtime.Time    `json:"executed_time,omitempty"`
+	CanceledTime  *txtime.Time    `json:"canceled_time,omitempty"`
+	FailedTime    *txtime.Time    `json:"failed_time,omitempty"`
+	FailedReason  string          `json:"failed_reason,omitempty"`
+	FrozenTime    *txtime.Time    `json:"frozen_time,omitempty"`
+}
+
+// NewContractStatusResult _
+func NewContractStatusResult(d *ContractDetail) *ContractStatusResult {
+	return &ContractStatusResult{
+		ContractID:    d.DOCTYPEID,
+		CCID:          d.CCID,
+		Status:        d.GetStatus(d.ts),
+		SignersCount:  d.SignersCount,
+		ApprovedCount: d.ApprovedCount,
+		Signs:         d.Signs,
+		ExecuteResult: d.ExecuteResult,
+		CancelResult:  d.CancelResult,
+		ExpiryTime:    d.ExpiryTime,
+		ExecutedTime:  d.ExecutedTime,
+		CanceledTime:  d.CanceledTime,
+		FailedTime:    d.FailedTime,
+		FailedReason:  d.FailedReason,
+		FrozenTime:    d.FrozenTime,
+	}
+}
+
+// MarshalPayload _
+func (r *ContractStatusResult) MarshalPayload() ([]byte, error) {
+	return json.Marshal(r)
+}
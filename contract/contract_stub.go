@@ -0,0 +1,1338 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/key-inside/kiesnet-ccpkg/stringset"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+)
+
+// ContractStub _
+type ContractStub struct {
+	stub     shim.ChaincodeStubInterface
+	events   *ContractEvents
+	config   *Config // cached
+	counters *CounterStub
+	records  map[string]map[string]*SignRecord // [contract ID][signer], written in the transaction (writes aren't readable until commit)
+}
+
+// NewContractStub _
+func NewContractStub(stub shim.ChaincodeStubInterface) *ContractStub {
+	return &ContractStub{stub: stub, events: &ContractEvents{}, counters: NewCounterStub(stub), records: map[string]map[string]*SignRecord{}}
+}
+
+// GetConfig returns the chaincode configuration, it's read once per transaction
+func (cb *ContractStub) GetConfig() (*Config, error) {
+	if nil == cb.config {
+		cfg, err := NewConfigStub(cb.stub).GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		cb.config = cfg
+	}
+	return cb.config, nil
+}
+
+// CreateKey creates the composite key of the signer's sign record
+func (cb *ContractStub) CreateKey(id, signer string) (string, error) {
+	key, err := cb.stub.CreateCompositeKey(SignRecordObjectType, []string{id, signer})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the sign record key")
+	}
+	return key, nil
+}
+
+// CreateHeaderKey creates the composite key of the contract header
+func (cb *ContractStub) CreateHeaderKey(id string) (string, error) {
+	key, err := cb.stub.CreateCompositeKey(ContractHeaderObjectType, []string{id})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the contract header key")
+	}
+	return key, nil
+}
+
+// CreateHash _
+func (cb *ContractStub) CreateHash(text string) string {
+	h := make([]byte, 32)
+	sha3.ShakeSum256(h, []byte(text))
+	return hex.EncodeToString(h)
+}
+
+// CreateContracts _
+// target is the snapshot of the callback target registered by the CCID, nil means the default.
+func (cb *ContractStub) CreateContracts(creator, ccid, document string, signers *stringset.Set, target *CallbackTarget, opts *ContractOptions) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	cfg, err := cb.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	scount := signers.Size()
+	expiry := opts.Expiry
+	if expiry < cfg.MinExpiry {
+		expiry = cfg.DefaultExpiry
+	}
+	expTime := txtime.New(ts.Add(time.Second * time.Duration(expiry)))
+
+	id := cb.CreateHash(creator + cb.stub.GetTxID())
+	// check id collision
+	header, err := cb.GetContractHeader(id)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return nil, NewContractError(client.ErrorCodeContractIDCollided, "contract ID collided")
+	}
+
+	contract := &Contract{
+		DOCTYPEID: id,
+		ContractHeader: ContractHeader{
+			Creator:        creator,
+			SignersCount:   scount,
+			ApprovedCount:  1, // creator has approved
+			CCID:           ccid,
+			Document:       document,
+			CallbackTarget: target,
+			CancelPolicy:   opts.CancelPolicy,
+			CreatedTime:    ts,
+			UpdatedTime:    ts,
+			UpdatedBy:      creator,
+			Counted:        true,
+			ExpiryTime:     expTime,
+			FinishedTime:   expTime,
+		},
+		signers: signers.Strings(),
+		ts:      ts,
+	}
+	sort.Strings(contract.signers) // deterministic state
+	if err = cb.putContractHeader(contract); err != nil {
+		return nil, err
+	}
+	if err = cb.putContractListKey(id); err != nil {
+		return nil, err
+	}
+
+	for _, signer := range contract.signers {
+		sign := &Sign{
+			Signer: signer,
+		}
+		if creator == signer {
+			sign.ApprovedTime = ts
+			contract.Sign = sign // creator's contract (for return)
+		}
+		if err = cb.PutSign(contract, sign, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = cb.SetEvent(contract, creator, ContractStatusCreated); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// GetContract _
+func (cb *ContractStub) GetContract(id, signer string) (*Contract, error) {
+	key, err := cb.CreateKey(id, signer)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cb.stub.GetState(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the contract state")
+	}
+	if nil == data {
+		if data, err = cb.stub.GetState(CreateLegacyKey(id, signer)); err != nil {
+			return nil, errors.Wrap(err, "failed to get the contract state")
+		}
+	}
+	if data != nil {
+		return cb.UnmarshalContract(data)
+	}
+	return nil, NotExistedContractError{id: id, signer: signer}
+}
+
+// GetContractByID returns any signer's contract of the ID
+func (cb *ContractStub) GetContractByID(id string) (*Contract, error) {
+	data, err := cb.getFirstValue(cb.stub.GetStateByPartialCompositeKey(SignRecordObjectType, []string{id}))
+	if err != nil {
+		return nil, err
+	}
+	if nil == data {
+		if data, err = cb.getFirstValue(cb.stub.GetStateByRange(CreateLegacyKeyRange(id))); err != nil {
+			return nil, err
+		}
+	}
+	if data != nil {
+		return cb.UnmarshalContract(data)
+	}
+	return nil, NotExistedContractError{id: id}
+}
+
+// getFirstValue returns the first value of the iterator, or nil if it's empty
+func (cb *ContractStub) getFirstValue(iter shim.StateQueryIteratorInterface, err error) ([]byte, error) {
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sign records")
+	}
+	defer iter.Close()
+	if iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the contract")
+		}
+		return kv.Value, nil
+	}
+	return nil, nil
+}
+
+// GetContractHeader returns the header document of the contract, or nil if it doesn't exist (legacy)
+func (cb *ContractStub) GetContractHeader(id string) (*ContractHeaderDoc, error) {
+	key, err := cb.CreateHeaderKey(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cb.stub.GetState(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the contract header state")
+	}
+	if data == nil {
+		return nil, nil
+	}
+	doc := &ContractHeaderDoc{}
+	if err = json.Unmarshal(data, doc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the contract header")
+	}
+	return doc, nil
+}
+
+// UnmarshalContract assembles the contract from the sign record data and the contract header
+func (cb *ContractStub) UnmarshalContract(data []byte) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+	record := &SignRecord{}
+	if err = json.Unmarshal(data, record); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the sign record")
+	}
+	doc, err := cb.GetContractHeader(record.DOCTYPEID)
+	if err != nil {
+		return nil, err
+	}
+	if nil == doc {
+		contract, err := cb.UnmarshalLegacyContract(data, record)
+		if err != nil {
+			return nil, err
+		}
+		contract.ts = ts
+		return contract, nil
+	}
+	return &Contract{
+		DOCTYPEID:      record.DOCTYPEID,
+		ContractHeader: doc.ContractHeader,
+		Sign:           record.Sign,
+		signers:        doc.Signers,
+		ts:             ts,
+	}, nil
+}
+
+// GetSignRecords returns all sign records of the contract
+func (cb *ContractStub) GetSignRecords(contract *Contract) ([]*SignRecord, error) {
+	if contract.legacy {
+		return cb.GetLegacySignRecords(contract.DOCTYPEID)
+	}
+
+	iter, err := cb.stub.GetStateByPartialCompositeKey(SignRecordObjectType, []string{contract.DOCTYPEID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sign records")
+	}
+	defer iter.Close()
+
+	written := cb.records[contract.DOCTYPEID]
+	records := []*SignRecord{}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the sign record")
+		}
+		record := &SignRecord{}
+		if err = json.Unmarshal(kv.Value, record); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal the sign record")
+		}
+		if w, ok := written[record.Sign.Signer]; ok {
+			record = w
+		}
+		records = append(records, record)
+	}
+	if len(written) > len(records) { // converted from the legacy in the transaction
+		records = records[:0]
+		for _, signer := range sortedSigners(written) {
+			records = append(records, written[signer])
+		}
+	}
+	return records, nil
+}
+
+// AggregateContract counts approvals and cancel requests from all sign records,
+// and sets the last signer if all signers have approved.
+// It reads other signers' records, so approvals must not call it. (MVCC conflict)
+func (cb *ContractStub) AggregateContract(contract *Contract) error {
+	records, err := cb.GetSignRecords(contract)
+	if err != nil {
+		return err
+	}
+	count, ccount := 0, 0
+	var last *Sign
+	for _, record := range records {
+		sign := record.Sign
+		if sign.Signer == contract.Sign.Signer {
+			sign = contract.Sign
+		}
+		if sign.CancelRequestedTime != nil {
+			ccount++
+		}
+		if sign.ApprovedTime != nil {
+			count++
+			if nil == last || sign.ApprovedTime.Cmp(last.ApprovedTime) >= 0 {
+				last = sign
+			}
+		}
+	}
+	contract.ApprovedCount = count
+	contract.CancelRequestedCount = ccount
+	if count == contract.SignersCount && last != nil {
+		contract.LastSigner = last.Signer
+	}
+	return nil
+}
+
+// GetContractDetail returns the contract with all signers' signs, approvals are aggregated
+func (cb *ContractStub) GetContractDetail(contract *Contract) (*ContractDetail, error) {
+	records, err := cb.GetSignRecords(contract)
+	if err != nil {
+		return nil, err
+	}
+	if err = cb.AggregateContract(contract); err != nil {
+		return nil, err
+	}
+	setSigners(contract, records)
+	hash, err := NewContractDigest(contract).Hash()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash the contract")
+	}
+	detail := &ContractDetail{Contract: contract, Signs: make([]*Sign, 0, len(records)), Hash: hex.EncodeToString(hash)}
+	for _, record := range records {
+		detail.Signs = append(detail.Signs, record.Sign)
+	}
+	return detail, nil
+}
+
+// putContractListKey puts the simple key listing the contract, when the header is stored first
+func (cb *ContractStub) putContractListKey(id string) error {
+	if err := cb.stub.PutState(ContractListKeyPrefix+id, IndexValue); err != nil {
+		return errors.Wrap(err, "failed to put the contract list key")
+	}
+	return nil
+}
+
+// PutContractHeader puts the contract header.
+// If the contract is legacy, all states of the contract are converted.
+func (cb *ContractStub) PutContractHeader(contract *Contract) error {
+	if contract.legacy {
+		return cb.UpdateContracts(contract)
+	}
+	return cb.putContractHeader(contract)
+}
+
+func (cb *ContractStub) putContractHeader(contract *Contract) error {
+	key, err := cb.CreateHeaderKey(contract.DOCTYPEID)
+	if err != nil {
+		return err
+	}
+	doc := &ContractHeaderDoc{
+		DOCTYPEID:      contract.DOCTYPEID,
+		Signers:        contract.signers,
+		DocumentFields: ParseDocumentFields(contract.Document),
+		ContractHeader: contract.ContractHeader,
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the contract header")
+	}
+	if err = cb.stub.PutState(key, data); err != nil {
+		return errors.Wrap(err, "failed to put the contract header state")
+	}
+	return nil
+}
+
+// PutSign puts the sign record of the signer with index fields of the contract,
+// and updates composite key indexes and counters changed from the old record. (nil means a new record)
+// Counters are updated only if the contract is counted.
+func (cb *ContractStub) PutSign(contract *Contract, sign *Sign, old *SignRecord) error {
+	record, err := cb.putSign(contract, sign, old, false)
+	if err != nil {
+		return err
+	}
+	if contract.Counted {
+		return cb.updateCounters(record, old)
+	}
+	return nil
+}
+
+// If overwrite is true, all index keys of the record are put, even if the old record has them. (reindex)
+func (cb *ContractStub) putSign(contract *Contract, sign *Sign, old *SignRecord, overwrite bool) (*SignRecord, error) {
+	key, err := cb.CreateKey(contract.DOCTYPEID, sign.Signer)
+	if err != nil {
+		return nil, err
+	}
+	record := contract.NewSignRecord(sign)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the sign record")
+	}
+	if err = cb.stub.PutState(key, data); err != nil {
+		return nil, errors.Wrap(err, "failed to put the sign record state")
+	}
+	if err = cb.updateIndexes(record, old, overwrite); err != nil {
+		return nil, err
+	}
+	if nil == cb.records[contract.DOCTYPEID] {
+		cb.records[contract.DOCTYPEID] = map[string]*SignRecord{}
+	}
+	cb.records[contract.DOCTYPEID][sign.Signer] = record
+	return record, nil
+}
+
+// updateCounters moves the count of the signer from the status of the old record to the status of the record
+func (cb *ContractStub) updateCounters(record, old *SignRecord) error {
+	status := record.SignerStatus()
+	if old != nil {
+		prev := old.SignerStatus()
+		if prev == status {
+			return nil
+		}
+		if err := cb.counters.AddCount(record.Sign.Signer, record.CCID, prev, -1); err != nil {
+			return err
+		}
+	}
+	return cb.counters.AddCount(record.Sign.Signer, record.CCID, status, 1)
+}
+
+// updateIndexes deletes stale index keys of the old record and puts new index keys of the record
+func (cb *ContractStub) updateIndexes(record, old *SignRecord, overwrite bool) error {
+	keys := map[string]bool{}
+	for objectType, attrs := range record.IndexAttributes() {
+		key, err := cb.stub.CreateCompositeKey(objectType, attrs)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the index key")
+		}
+		keys[key] = true
+	}
+	oldKeys := map[string]bool{}
+	if old != nil {
+		for objectType, attrs := range old.IndexAttributes() {
+			key, err := cb.stub.CreateCompositeKey(objectType, attrs)
+			if err != nil {
+				return errors.Wrap(err, "failed to create the index key")
+			}
+			oldKeys[key] = true
+		}
+	}
+	// sorted for deterministic write sets
+	for _, key := range sortedKeys(oldKeys) {
+		if !keys[key] {
+			if err := cb.stub.DelState(key); err != nil {
+				return errors.Wrap(err, "failed to delete the index")
+			}
+		}
+	}
+	for _, key := range sortedKeys(keys) {
+		if overwrite || !oldKeys[key] {
+			if err := cb.stub.PutState(key, IndexValue); err != nil {
+				return errors.Wrap(err, "failed to put the index")
+			}
+		}
+	}
+	return nil
+}
+
+// setDisapprover sets the disapprover of the canceled contract from sign records, if it's unknown
+func setDisapprover(contract *Contract, records []*SignRecord) {
+	if nil == contract.CanceledTime || contract.Disapprover != "" {
+		return
+	}
+	for _, record := range records {
+		if record.Sign.DisapprovedTime != nil {
+			contract.Disapprover = record.Sign.Signer
+			return
+		}
+	}
+}
+
+// forEachContractHeaders calls fn with contract headers, at most 'size' contracts from the start contract ID.
+// It returns the contract ID of the next batch (empty means done), and the number of processed contracts.
+// Paginated queries aren't allowed in update transactions, and range queries don't accept composite keys.
+// So with CouchDB, the batch is a rich query bounded by the start contract ID,
+// and with LevelDB, it's a range query of contract list keys (CTL_{id}) from the start contract ID.
+func (cb *ContractStub) forEachContractHeaders(start string, size int, fn func([]byte) error) (string, int, error) {
+	cfg, err := cb.GetConfig()
+	if err != nil {
+		return "", 0, err
+	}
+	rich := cfg.UseRichQuery()
+	var iter shim.StateQueryIteratorInterface
+	if rich {
+		iter, err = cb.stub.GetQueryResult(CreateQueryContractHeadersFrom(start))
+	} else {
+		iter, err = cb.stub.GetStateByRange(ContractListKeyPrefix+start, ContractListKeyPrefix+string(utf8.MaxRune))
+	}
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to get contract headers")
+	}
+	defer iter.Close()
+
+	count := 0
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return "", 0, errors.Wrap(err, "failed to get the contract header")
+		}
+		id := strings.TrimPrefix(kv.Key, ContractListKeyPrefix)
+		if rich {
+			_, attrs, err := cb.stub.SplitCompositeKey(kv.Key)
+			if err != nil || len(attrs) != 1 {
+				return "", 0, errors.Errorf("invalid contract header key: [%s]", kv.Key)
+			}
+			id = attrs[0]
+		}
+		if count >= size {
+			return id, count, nil
+		}
+		data := kv.Value
+		if !rich {
+			key, err := cb.CreateHeaderKey(id)
+			if err != nil {
+				return "", 0, err
+			}
+			if data, err = cb.stub.GetState(key); err != nil || nil == data {
+				return "", 0, errors.Errorf("failed to get the contract header: [%s]", id)
+			}
+		}
+		if err = fn(data); err != nil {
+			return "", 0, err
+		}
+		count++
+	}
+	return "", count, nil
+}
+
+// ReindexContracts rewrites sign records and all index keys of contracts. (see forEachContractHeaders)
+func (cb *ContractStub) ReindexContracts(start string, size int) (string, int, error) {
+	return cb.forEachContractHeaders(start, size, cb.reindexContract)
+}
+
+// RewriteContractHeaders rewrites contract headers with document fields. (see forEachContractHeaders)
+func (cb *ContractStub) RewriteContractHeaders(start string, size int) (string, int, error) {
+	return cb.forEachContractHeaders(start, size, func(data []byte) error {
+		doc := &ContractHeaderDoc{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			return errors.Wrap(err, "failed to unmarshal the contract header")
+		}
+		return cb.putContractHeader(&Contract{
+			DOCTYPEID:      doc.DOCTYPEID,
+			ContractHeader: doc.ContractHeader,
+			signers:        doc.Signers,
+		})
+	})
+}
+
+func (cb *ContractStub) reindexContract(data []byte) error {
+	doc := &ContractHeaderDoc{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return errors.Wrap(err, "failed to unmarshal the contract header")
+	}
+	contract := &Contract{
+		DOCTYPEID:      doc.DOCTYPEID,
+		ContractHeader: doc.ContractHeader,
+		signers:        doc.Signers,
+	}
+	records, err := cb.GetSignRecords(contract)
+	if err != nil {
+		return err
+	}
+	if "" == contract.Disapprover {
+		if setDisapprover(contract, records); contract.Disapprover != "" {
+			if err = cb.putContractHeader(contract); err != nil {
+				return err
+			}
+		}
+	}
+	for _, record := range records {
+		// all index keys are put (existing keys are overwritten), and stale keys of the stored record
+		// (ex. canceled before the disapprover is known) are deleted. counters aren't changed
+		if _, err = cb.putSign(contract, record.Sign, record, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountContracts counts contracts which aren't counted yet. (see forEachContractHeaders)
+func (cb *ContractStub) CountContracts(start string, size int) (string, int, error) {
+	return cb.forEachContractHeaders(start, size, cb.countContract)
+}
+
+func (cb *ContractStub) countContract(data []byte) error {
+	doc := &ContractHeaderDoc{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return errors.Wrap(err, "failed to unmarshal the contract header")
+	}
+	if doc.Counted {
+		return nil
+	}
+	contract := &Contract{
+		DOCTYPEID:      doc.DOCTYPEID,
+		ContractHeader: doc.ContractHeader,
+		signers:        doc.Signers,
+	}
+	records, err := cb.GetSignRecords(contract)
+	if err != nil {
+		return err
+	}
+	contract.Counted = true
+	if err = cb.putContractHeader(contract); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err = cb.updateCounters(record, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSigners(m map[string]*SignRecord) []string {
+	signers := make([]string, 0, len(m))
+	for signer := range m {
+		signers = append(signers, signer)
+	}
+	sort.Strings(signers)
+	return signers
+}
+
+// ApproveContract reads only the header and the signer's sign record, and writes only the signer's sign record,
+// indexes and counter, so concurrent approvals don't conflict. Approvals are aggregated by 'execute'.
+// If sig isn't nil, it's verified by the signer's public key over the contract hash, and stored on the sign.
+func (cb *ContractStub) ApproveContract(contract *Contract, comment string, sig []byte) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	if err = contract.AssertSignable(ts); err != nil {
+		return nil, err
+	}
+
+	if contract.legacy { // convert it
+		if err = cb.UpdateContracts(contract); err != nil {
+			return nil, err
+		}
+	}
+
+	var signature *client.Signature
+	if sig != nil {
+		if signature, err = cb.VerifySignature(contract, sig); err != nil {
+			return nil, err
+		}
+	}
+
+	prev := *contract.Sign // copy
+	contract.Sign.ApprovedTime = ts
+	contract.Sign.Comment = comment
+	contract.Sign.Signature = signature
+	contract.ApprovedCount = 0 // unknown without other signers' records, omitted
+
+	if err = cb.PutSign(contract, contract.Sign, contract.NewSignRecord(&prev)); err != nil {
+		return nil, err
+	}
+
+	if err = cb.SetEvent(contract, contract.Sign.Signer, ContractStatusApproved); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// setSigners sets signers of the contract by the records, if it doesn't know them (legacy)
+func setSigners(contract *Contract, records []*SignRecord) {
+	if contract.signers != nil {
+		return
+	}
+	contract.signers = make([]string, 0, len(records))
+	for _, record := range records {
+		contract.signers = append(contract.signers, record.Sign.Signer)
+	}
+}
+
+// VerifySignature verifies the signer's signature over the contract hash by the registered public key
+func (cb *ContractStub) VerifySignature(contract *Contract, sig []byte) (*client.Signature, error) {
+	key, err := NewPublicKeyStub(cb.stub).GetSignerPublicKey(contract.Sign.Signer)
+	if err != nil {
+		return nil, err
+	}
+	if nil == contract.signers { // legacy contracts may not know signers
+		records, err := cb.GetSignRecords(contract)
+		if err != nil {
+			return nil, err
+		}
+		setSigners(contract, records)
+	}
+	hash, err := NewContractDigest(contract).Hash()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash the contract")
+	}
+	return key.Verify(hash, sig)
+}
+
+// ExecuteContract marks the contract as executed
+func (cb *ContractStub) ExecuteContract(contract *Contract, executor string) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	contract.ExecutedTime = ts
+	contract.FinishedTime = ts
+	contract.UpdatedTime = ts
+	contract.UpdatedBy = executor
+	contract.FailedTime = nil
+	contract.FailedReason = ""
+
+	// update all other signers
+	if err = cb.UpdateContracts(contract); err != nil {
+		return nil, err
+	}
+
+	if err = cb.SetEvent(contract, executor, ContractStatusExecuted); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// FailExecution marks the contract as execution failed.
+// The contract stays unfinished, so it can be retried or canceled.
+// The transaction is committed with the failure, so writes of the failed callback are committed too.
+// (callbacks must not write the state before returning an error)
+func (cb *ContractStub) FailExecution(contract *Contract, executor, reason string) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	contract.FailedTime = ts
+	contract.FailedReason = reason
+	contract.UpdatedTime = ts
+	contract.UpdatedBy = executor
+
+	// index fields are not changed
+	if err = cb.PutContractHeader(contract); err != nil {
+		return nil, err
+	}
+
+	if err = cb.SetEvent(contract, executor, ContractStatusExecutionFailed); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// RequestCancelContract writes the signer's cancel request (quorum cancel policy),
+// and aggregates cancel requests of all signers.
+func (cb *ContractStub) RequestCancelContract(contract *Contract) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	if contract.Sign.CancelRequestedTime != nil {
+		return nil, NewContractError(client.ErrorCodeAlreadyCancelRequested, "already requested to cancel")
+	}
+
+	if contract.legacy { // convert it
+		if err = cb.UpdateContracts(contract); err != nil {
+			return nil, err
+		}
+	}
+
+	prev := *contract.Sign // copy
+	contract.Sign.CancelRequestedTime = ts
+
+	if err = cb.PutSign(contract, contract.Sign, contract.NewSignRecord(&prev)); err != nil {
+		return nil, err
+	}
+	if err = cb.AggregateContract(contract); err != nil {
+		return nil, err
+	}
+
+	if err = cb.SetEvent(contract, contract.Sign.Signer, ContractStatusCancelRequested); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// CancelContract _
+func (cb *ContractStub) CancelContract(contract *Contract, canceler string) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	contract.CanceledTime = ts
+	contract.FinishedTime = ts
+	contract.UpdatedTime = ts
+	contract.UpdatedBy = canceler
+
+	// update all other signers
+	if err = cb.UpdateContracts(contract); err != nil {
+		return nil, err
+	}
+
+	if err = cb.SetEvent(contract, canceler, ContractStatusCanceled); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// FreezeContract marks the contract as frozen, signers can't approve or disapprove it until it's unfrozen.
+func (cb *ContractStub) FreezeContract(contract *Contract, admin, reason string) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	contract.FrozenTime = ts
+	contract.UpdatedTime = ts
+	contract.UpdatedBy = admin
+	contract.AddAdminAction(AdminActionFreeze, admin, reason, ts)
+
+	// index fields are not changed
+	if err = cb.PutContractHeader(contract); err != nil {
+		return nil, err
+	}
+
+	if err = cb.SetEvent(contract, admin, ContractStatusFrozen); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// UnfreezeContract _
+func (cb *ContractStub) UnfreezeContract(contract *Contract, admin, reason string) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	contract.FrozenTime = nil
+	contract.UpdatedTime = ts
+	contract.UpdatedBy = admin
+	contract.AddAdminAction(AdminActionUnfreeze, admin, reason, ts)
+
+	// index fields are not changed
+	if err = cb.PutContractHeader(contract); err != nil {
+		return nil, err
+	}
+
+	if err = cb.SetEvent(contract, admin, ContractStatusUnfrozen); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// DisapproveContract _
+func (cb *ContractStub) DisapproveContract(contract *Contract, comment string) (*Contract, error) {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	if err = contract.AssertSignable(ts); err != nil {
+		return nil, err
+	}
+
+	contract.Sign.DisapprovedTime = ts
+	contract.Sign.Comment = comment
+	contract.UpdatedTime = ts
+	contract.UpdatedBy = contract.Sign.Signer
+	contract.CanceledTime = ts
+	contract.Disapprover = contract.Sign.Signer
+	contract.FinishedTime = ts
+
+	// update all other signers
+	if err = cb.UpdateContracts(contract); err != nil {
+		return nil, err
+	}
+
+	if err = cb.SetEvent(contract, contract.Sign.Signer, ContractStatusDisapproved); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// UpdateContracts updates the contract header and index fields of all signers' records.
+// If the contract is legacy, legacy states are converted to composite keys and deleted.
+func (cb *ContractStub) UpdateContracts(updater *Contract) error {
+	records, err := cb.GetSignRecords(updater)
+	if err != nil {
+		return err
+	}
+
+	legacy := updater.legacy
+	if legacy {
+		if err = cb.DeleteLegacyContract(updater, records); err != nil {
+			return err
+		}
+		updater.legacy = false
+		setDisapprover(updater, records) // legacy contracts don't know the disapprover
+		if err = cb.putContractListKey(updater.DOCTYPEID); err != nil {
+			return err
+		}
+	}
+
+	if err = cb.putContractHeader(updater); err != nil {
+		return err
+	}
+
+	for _, updatee := range records {
+		sign := updatee.Sign
+		if sign.Signer == updater.Sign.Signer {
+			sign = updater.Sign
+		}
+		old := updatee
+		if legacy {
+			old = nil // legacy records don't have indexes
+		}
+		if err = cb.PutSign(updater, sign, old); err != nil {
+			return errors.Wrap(err, "failed to update a contract")
+		}
+	}
+
+	return nil
+}
+
+// SetEvent appends the transition event and sets all events of the transaction as the chaincode event
+func (cb *ContractStub) SetEvent(contract *Contract, actor, status string) error {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the timestamp")
+	}
+	signers := append([]string{}, contract.signers...)
+	sort.Strings(signers) // deterministic payload
+	cb.events.Events = append(cb.events.Events, &ContractEvent{
+		ContractID: contract.DOCTYPEID,
+		CCID:       contract.CCID,
+		Actor:      actor,
+		Status:     status,
+		Signers:    signers,
+		Time:       ts,
+	})
+	data, err := cb.events.MarshalPayload()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the event")
+	}
+	if err = cb.stub.SetEvent(ContractEventName, data); err != nil {
+		return errors.Wrap(err, "failed to set the event")
+	}
+	return nil
+}
+
+// CreateCallbackTargetKey _
+func (cb *ContractStub) CreateCallbackTargetKey(ccid string) string {
+	return "CCB_" + ccid
+}
+
+// GetCallbackTarget returns the callback target registered by the CCID, or nil if not registered.
+func (cb *ContractStub) GetCallbackTarget(ccid string) (*CallbackTarget, error) {
+	data, err := cb.stub.GetState(cb.CreateCallbackTargetKey(ccid))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the callback target state")
+	}
+	if data == nil {
+		return nil, nil
+	}
+	target := &CallbackTarget{}
+	if err = json.Unmarshal(data, target); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the callback target")
+	}
+	return target, nil
+}
+
+// PutCallbackTarget registers the callback target of the CCID
+func (cb *ContractStub) PutCallbackTarget(ccid string, target *CallbackTarget) error {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the timestamp")
+	}
+	target.UpdatedTime = ts
+	data, err := json.Marshal(target)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the callback target")
+	}
+	if err = cb.stub.PutState(cb.CreateCallbackTargetKey(ccid), data); err != nil {
+		return errors.Wrap(err, "failed to put the callback target state")
+	}
+	return nil
+}
+
+// CreateCallbackAllowanceKey _
+func (cb *ContractStub) CreateCallbackAllowanceKey(chaincode string) string {
+	return "CCA_" + chaincode
+}
+
+// GetCallbackAllowance returns the allowance of the chaincode, or nil if it doesn't allow any CCID.
+func (cb *ContractStub) GetCallbackAllowance(chaincode string) (*CallbackAllowance, error) {
+	data, err := cb.stub.GetState(cb.CreateCallbackAllowanceKey(chaincode))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the callback allowance state")
+	}
+	if data == nil {
+		return nil, nil
+	}
+	allowance := &CallbackAllowance{}
+	if err = json.Unmarshal(data, allowance); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the callback allowance")
+	}
+	return allowance, nil
+}
+
+// PutCallbackAllowance _
+func (cb *ContractStub) PutCallbackAllowance(allowance *CallbackAllowance) error {
+	ts, err := txtime.GetTime(cb.stub)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the timestamp")
+	}
+	allowance.UpdatedTime = ts
+	data, err := json.Marshal(allowance)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the callback allowance")
+	}
+	if err = cb.stub.PutState(cb.CreateCallbackAllowanceKey(allowance.DOCTYPEID), data); err != nil {
+		return errors.Wrap(err, "failed to put the callback allowance state")
+	}
+	return nil
+}
+
+// AssertCallbackAllowed asserts that the target chaincode allows the CCID to use it as the callback target.
+// The CCID itself is always allowed. Allowances are stored on this channel, so a chaincode on another channel
+// allows the CCID by 'callback/allow' of the same name chaincode on this channel.
+func (cb *ContractStub) AssertCallbackAllowed(ccid string, target *CallbackTarget) error {
+	if target.Chaincode == ccid {
+		return nil
+	}
+	allowance, err := cb.GetCallbackAllowance(target.Chaincode)
+	if err != nil {
+		return err
+	}
+	if nil == allowance || !allowance.IsAllowed(ccid) {
+		return NewContractError(client.ErrorCodeInvalidAccess, "the callback target doesn't allow the chaincode: ["+target.Chaincode+"]")
+	}
+	return nil
+}
+
+// GetQueryContracts _
+// ccids - CCIDs of contracts, empty (nil) means all
+// option - 1 of [finished, unfinished, approved, unsigned, executed, canceled, disapproved, expired, all]
+func (cb *ContractStub) GetQueryContracts(kid string, ccids []string, opt, bookmark string) (*QueryResult, error) {
+	cfg, err := cb.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.UseRichQuery() {
+		return cb.GetIndexedContracts(kid, ccids, opt, bookmark)
+	}
+
+	ts, err := txtime.GetTime(cb.stub)
+	if nil != err {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	query := ""
+	if len(ccids) == 1 {
+		query = CreateQueryContractsBySignerOption(kid, ccids[0], opt, ts)
+	} else {
+		query = CreateQueryContractsBySignerCCIDsOption(kid, ccids, opt, ts)
+	}
+
+	iter, meta, err := cb.stub.GetQueryResultWithPagination(query, cfg.FetchSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	return NewQueryResult(meta, &contractsIterator{iter, cb})
+}
+
+// GetIndexedContracts queries contracts by composite key indexes, without rich queries. (LevelDB)
+// option - 1 of [finished, unfinished, approved, unsigned, executed, canceled, disapproved, expired, all]
+// Across chaincodes, cross indexes are used and filtered by CCIDs, so pages may have less records than the fetch size.
+func (cb *ContractStub) GetIndexedContracts(kid string, ccids []string, opt, bookmark string) (*QueryResult, error) {
+	cfg, err := cb.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := txtime.GetTime(cb.stub)
+	if nil != err {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+	after := txtime.New(ts.Add(time.Nanosecond)) // exclusive 'now'
+
+	objectType := IndexCreatedDesc // all|created_time|desc
+	start := ""                    // first time key
+	switch opt {
+	case "finished": // finished|finished_time|desc
+		objectType = IndexFinishedDesc
+		start = DescTimeKey(ts)
+	case "executed": // executed|executed_time|desc
+		objectType = IndexExecuted
+	case "canceled": // canceled|canceled_time|desc
+		objectType = IndexCanceled
+	case "disapproved": // disapproved|canceled_time|desc
+		objectType = IndexDisapproved
+	case "expired": // expired|expiry_time|desc
+		objectType = IndexExpiryDesc
+		start = DescTimeKey(ts)
+	case "unfinished": // unfinished|finished_time|asc
+		objectType = IndexFinishedAsc
+		start = AscTimeKey(after)
+	case "approved": // unfinished|approved|expiry_time|asc
+		objectType = IndexApproved
+		start = AscTimeKey(after)
+	case "unsigned": // unfinished|unsigned|expiry_time|asc
+		objectType = IndexUnsigned
+		start = AscTimeKey(after)
+	}
+
+	keys := []string{kid}
+	var filter map[string]bool
+	if len(ccids) == 1 {
+		keys = append(keys, ccids[0])
+	} else {
+		objectType = CrossIndex(objectType)
+		if len(ccids) > 0 {
+			filter = map[string]bool{}
+			for _, ccid := range ccids {
+				filter[ccid] = true
+			}
+		}
+	}
+	// the bookmark is the inclusive start key, so the first page starts from the time key
+	if bookmark == "" && start != "" {
+		if bookmark, err = cb.stub.CreateCompositeKey(objectType, append(keys, start)); err != nil {
+			return nil, errors.Wrap(err, "failed to create the bookmark")
+		}
+	}
+
+	iter, meta, err := cb.stub.GetStateByPartialCompositeKeyWithPagination(objectType, keys, cfg.FetchSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	return NewQueryResult(meta, &indexedContractsIterator{StateQueryIteratorInterface: iter, cb: cb, ccids: filter})
+}
+
+// SearchContracts queries contract headers by document fields. (CouchDB only)
+// If the kid is set, it returns the signer's contracts, or status results of the chaincode (ccid).
+func (cb *ContractStub) SearchContracts(kid, ccid string, selector map[string]interface{}, bookmark string) (*QueryResult, error) {
+	cfg, err := cb.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.UseRichQuery() {
+		return nil, NewContractError(client.ErrorCodeUnsupported, "search requires the rich query (couchdb)")
+	}
+
+	query, err := CreateQuerySearchContracts(kid, ccid, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, meta, err := cb.stub.GetQueryResultWithPagination(query, cfg.FetchSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	return NewQueryResult(meta, &headersIterator{iter, cb, kid})
+}
+
+// MarshalAggregatedContract aggregates approvals and marshals the contract
+func (cb *ContractStub) MarshalAggregatedContract(contract *Contract) ([]byte, error) {
+	if err := cb.AggregateContract(contract); err != nil {
+		return nil, err
+	}
+	data, err := contract.MarshalPayload()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the contract")
+	}
+	return data, nil
+}
+
+// contractsIterator assembles contracts from sign records
+type contractsIterator struct {
+	shim.StateQueryIteratorInterface
+	cb *ContractStub
+}
+
+// Next implements shim.StateQueryIteratorInterface
+func (it *contractsIterator) Next() (*queryresult.KV, error) {
+	kv, err := it.StateQueryIteratorInterface.Next()
+	if err != nil {
+		return nil, err
+	}
+	contract, err := it.cb.UnmarshalContract(kv.Value)
+	if err != nil {
+		return nil, err
+	}
+	data, err := it.cb.MarshalAggregatedContract(contract)
+	if err != nil {
+		return nil, err
+	}
+	return &queryresult.KV{Namespace: kv.Namespace, Key: kv.Key, Value: data}, nil
+}
+
+// indexedContractsIterator assembles contracts from index keys.
+// If CCIDs are set, index keys of other chaincodes are skipped. (cross indexes)
+type indexedContractsIterator struct {
+	shim.StateQueryIteratorInterface
+	cb    *ContractStub
+	ccids map[string]bool
+	next  *queryresult.KV
+	err   error
+}
+
+// HasNext implements shim.StateQueryIteratorInterface
+func (it *indexedContractsIterator) HasNext() bool {
+	for nil == it.next && nil == it.err && it.StateQueryIteratorInterface.HasNext() {
+		kv, err := it.StateQueryIteratorInterface.Next()
+		if err != nil {
+			it.err = err
+			break
+		}
+		if it.ccids != nil {
+			_, attrs, err := it.cb.stub.SplitCompositeKey(kv.Key)
+			if err != nil {
+				it.err = errors.Wrap(err, "failed to split the index key")
+				break
+			}
+			if len(attrs) != 4 || !it.ccids[attrs[2]] { // signer, time key, ccid, contract ID
+				continue
+			}
+		}
+		it.next = kv
+	}
+	return it.next != nil || it.err != nil
+}
+
+// Next implements shim.StateQueryIteratorInterface
+func (it *indexedContractsIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no more index keys")
+	}
+	if it.err != nil {
+		return nil, it.err
+	}
+	kv := it.next
+	it.next = nil
+	_, attrs, err := it.cb.stub.SplitCompositeKey(kv.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to split the index key")
+	}
+	if len(attrs) != 4 { // signer, (ccid, time key) or (time key, ccid), contract ID
+		return nil, errors.New("invalid index key")
+	}
+	contract, err := it.cb.GetContract(attrs[3], attrs[0])
+	if err != nil {
+		return nil, err
+	}
+	data, err := it.cb.MarshalAggregatedContract(contract)
+	if err != nil {
+		return nil, err
+	}
+	return &queryresult.KV{Namespace: kv.Namespace, Key: kv.Key, Value: data}, nil
+}
+
+// headersIterator assembles contracts (kid) or status results (ccid) from contract headers
+type headersIterator struct {
+	shim.StateQueryIteratorInterface
+	cb  *ContractStub
+	kid string
+}
+
+// Next implements shim.StateQueryIteratorInterface
+func (it *headersIterator) Next() (*queryresult.KV, error) {
+	kv, err := it.StateQueryIteratorInterface.Next()
+	if err != nil {
+		return nil, err
+	}
+	doc := &ContractHeaderDoc{}
+	if err = json.Unmarshal(kv.Value, doc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the contract header")
+	}
+	var data []byte
+	if it.kid != "" {
+		contract, err := it.cb.GetContract(doc.DOCTYPEID, it.kid)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = it.cb.MarshalAggregatedContract(contract); err != nil {
+			return nil, err
+		}
+	} else {
+		contract, err := it.cb.GetContractByID(doc.DOCTYPEID)
+		if err != nil {
+			return nil, err
+		}
+		detail, err := it.cb.GetContractDetail(contract)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = NewContractStatusResult(detail).MarshalPayload(); err != nil {
+			return nil, err
+		}
+	}
+	return &queryresult.KV{Namespace: kv.Namespace, Key: kv.Key, Value: data}, nil
+}
@@ -0,0 +1,688 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/base64"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/key-inside/kiesnet-ccpkg/ccid"
+	"github.com/key-inside/kiesnet-ccpkg/stringset"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+	"github.com/pkg/errors"
+)
+
+// params[0] : contract ID
+// params[1] : comment (optional)
+// transient[kiesnet-contract/signature] : signature over the contract hash (optional)
+// It doesn't execute the contract, 'execute' aggregates approvals and executes it.
+func contractApprove(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) < 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1+")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, true)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+	comment := ""
+	if len(params) > 1 {
+		comment = params[1]
+	}
+
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return responseError(err, "failed to get the transient")
+	}
+	sig := transient[client.TransientSignature]
+	if sig != nil && len(sig) == 0 {
+		return responseCode(client.ErrorCodeInvalidSignature, "empty signature")
+	}
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		return responseError(err, "failed to approve the contract")
+	}
+	contract, err = cb.ApproveContract(contract, comment, sig)
+	if err != nil {
+		return responseError(err, "failed to approve the contract")
+	}
+
+	// approve callback (opt-in), it can veto the approval
+	if _, err = invokeApproveContract(stub, contract); err != nil {
+		return responseCode(client.ErrorCodeCallbackFailed, "failed to approve the contract|"+err.Error())
+	}
+
+	return response(contract)
+}
+
+// params[0] : contract ID
+func contractCallbacks(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, false)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		return responseError(err, "failed to get the callback results")
+	}
+
+	return response(&CallbackResults{
+		ContractID: contract.DOCTYPEID,
+		Execute:    contract.ExecuteResult,
+		Cancel:     contract.CancelResult,
+	})
+}
+
+// params[0] : contract ID
+func contractCancel(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	direct := isDirect(ccid)
+
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, true)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+
+	ts, err := txtime.GetTime(stub)
+	if err != nil {
+		return responseError(err, "failed to cancel the contract")
+	}
+
+	cb := NewContractStub(stub)
+	signer := true
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		if _, ok := err.(NotExistedContractError); !ok || direct {
+			return responseError(err, "failed to cancel the contract")
+		}
+		// invoker is not a signer, it's allowed by the CCID policy
+		if contract, err = cb.GetContractByID(id); err != nil {
+			return responseError(err, "failed to cancel the contract")
+		}
+		signer = false
+	}
+	// validate
+	if !direct && contract.CCID != ccid {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+	if contract.FinishedTime != nil && ts.Cmp(contract.FinishedTime) >= 0 { // ts >= finished_time => expired
+		return responseCode(client.ErrorCodeAlreadyFinished, "already finished contract")
+	}
+	if contract.FrozenTime != nil {
+		return responseCode(client.ErrorCodeFrozen, "frozen contract")
+	}
+
+	policy := contract.GetCancelPolicy()
+	switch policy.Type {
+	case CancelPolicyCreator:
+		if kid != contract.Creator {
+			return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+		}
+	case CancelPolicySigner:
+		if direct || !signer {
+			return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+		}
+	case CancelPolicyCCID:
+		if direct {
+			return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+		}
+	case CancelPolicyQuorum:
+		if !signer {
+			return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+		}
+		if contract, err = cb.RequestCancelContract(contract); err != nil {
+			return responseError(err, "failed to cancel the contract")
+		}
+		if contract.CancelRequestedCount < policy.Threshold {
+			return response(contract) // not yet
+		}
+	default:
+		return responseCode(client.ErrorCodeInternal, "unknown cancel policy")
+	}
+
+	// the CCID doesn't know the cancellation if it's invoked directly, the result is written with the cancellation
+	if direct {
+		result, err := invokeCancelContract(stub, contract)
+		if err != nil {
+			return responseCode(client.ErrorCodeCallbackFailed, "failed to cancel the contract|"+err.Error())
+		}
+		contract.CancelResult = result
+	}
+
+	if contract, err = cb.CancelContract(contract, kid); err != nil {
+		return responseError(err, "failed to cancel the contract")
+	}
+
+	return response(contract)
+}
+
+// params[0] : contract ID
+// Chaincode to chaincode query, only the CCID created the contract can get the status.
+func contractStatus(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	id := params[0]
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContractByID(id)
+	if err != nil {
+		return responseError(err, "failed to get the contract status")
+	}
+	if contract.CCID != ccid {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	detail, err := cb.GetContractDetail(contract)
+	if err != nil {
+		return responseError(err, "failed to get the contract status")
+	}
+
+	return response(NewContractStatusResult(detail))
+}
+
+// params[0] : document (JSON string)
+// params[1] : expiry (duration represented by int64 seconds, multi-sig only) or options JSON object
+// params[2:] : signers' KID (exclude invoker, max 'max_signers' - 1)
+func contractCreate(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	cb := NewContractStub(stub)
+	cfg, err := cb.GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+	if cfg.IsBlockedCCID(ccid) {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+	reg, err := NewRegistrationStub(stub).GetCreatorRegistration(ccid, cfg)
+	if err != nil {
+		return responseError(err, "failed to create a contract")
+	}
+	maxSigners := cfg.MaxSigners
+	if reg != nil {
+		maxSigners = reg.GetMaxSigners(cfg)
+	}
+
+	if len(params) < 3 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 3+")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, true)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	signers := stringset.New(kid)
+	signers.AppendSlice(params[2:])
+
+	if signers.Size() < 2 {
+		return responseCode(client.ErrorCodeNotEnoughSigners, "not enough signers")
+	} else if signers.Size() > maxSigners {
+		return responseCode(client.ErrorCodeTooManySigners, "too many signers")
+	}
+
+	opts, err := ParseContractOptions(params[1])
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidOptions, err.Error())
+	}
+	if opts.CancelPolicy != nil {
+		if err = opts.CancelPolicy.Validate(signers.Size()); err != nil {
+			return responseCode(client.ErrorCodeInvalidOptions, err.Error())
+		}
+	}
+
+	document := params[0]
+	if reg != nil {
+		if err = reg.AssertDocument(document); err != nil {
+			return responseError(err, "failed to create a contract")
+		}
+	}
+
+	// callback target of the options or registered by the CCID (snapshot), it's checked again by current limits and allowances
+	var target *CallbackTarget
+	if t := opts.CallbackTarget; t != nil {
+		target = NewCallbackTarget(ccid, t.Chaincode, t.Channel, t.Execute, t.Cancel, t.Approve)
+	} else if target, err = cb.GetCallbackTarget(ccid); err != nil {
+		return responseError(err, "failed to create a contract")
+	}
+	// the default callbacks of the CCID are checked by the limits as well
+	effective := target
+	if nil == effective {
+		effective = NewCallbackTarget(ccid, "", "", "", "", "")
+	}
+	if reg != nil {
+		if err = reg.AssertCallbackTarget(effective); err != nil {
+			return responseError(err, "failed to create a contract")
+		}
+	}
+	if target != nil {
+		if err = cb.AssertCallbackAllowed(ccid, target); err != nil {
+			return responseError(err, "failed to create a contract")
+		}
+	}
+
+	contract, err := cb.CreateContracts(kid, ccid, document, signers, target, opts)
+	if err != nil {
+		return responseError(err, "failed to create a contract")
+	}
+
+	return response(contract)
+}
+
+// params[0] : contract ID
+// Any signer or the CCID created the contract can get the detail.
+func contractDetail(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, false)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+
+	cb := NewContractStub(stub)
+	signer := true
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		if _, ok := err.(NotExistedContractError); !ok {
+			return responseError(err, "failed to get the contract")
+		}
+		// invoker is not a signer, it must be the CCID
+		if contract, err = cb.GetContractByID(id); err != nil {
+			return responseError(err, "failed to get the contract")
+		}
+		if contract.CCID != ccid {
+			return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+		}
+		signer = false
+	}
+
+	detail, err := cb.GetContractDetail(contract)
+	if err != nil {
+		return responseError(err, "failed to get the contract")
+	}
+	if !signer {
+		detail.Sign = nil // not the invoker's
+	}
+
+	return response(detail)
+}
+
+// params[0] : contract ID
+// params[1] : comment (optional)
+func contractDisapprove(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) < 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1+")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, true)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+	comment := ""
+	if len(params) > 1 {
+		comment = params[1]
+	}
+
+	ts, err := txtime.GetTime(stub)
+	if err != nil {
+		return responseError(err, "failed to disapprove the contract")
+	}
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		return responseError(err, "failed to disapprove the contract")
+	}
+	if err = contract.AssertSignable(ts); err != nil {
+		return responseError(err, "failed to disapprove the contract")
+	}
+
+	// cancel contract, the result is written with the disapproval
+	result, err := invokeCancelContract(stub, contract)
+	if err != nil {
+		return responseCode(client.ErrorCodeCallbackFailed, "failed to cancel the contract|"+err.Error())
+	}
+	contract.CancelResult = result
+	if contract, err = cb.DisapproveContract(contract, comment); err != nil {
+		return responseError(err, "failed to disapprove the contract")
+	}
+
+	return response(contract)
+}
+
+// params[0] : contract ID
+// It aggregates approvals, and executes the contract approved by all signers. (approvals don't execute it)
+// It also retries the execution failed contract.
+func contractExecute(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, true)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		if _, ok := err.(NotExistedContractError); !ok {
+			return responseError(err, "failed to execute the contract")
+		}
+		// invoker is not a signer, it must be the CCID
+		if contract, err = cb.GetContractByID(id); err != nil {
+			return responseError(err, "failed to execute the contract")
+		}
+		if contract.CCID != ccid {
+			return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+		}
+	}
+	// validate
+	if err = contract.AssertExecutable(contract.ts); err != nil {
+		return responseError(err, "failed to execute the contract")
+	}
+
+	if err = cb.AggregateContract(contract); err != nil {
+		return responseError(err, "failed to execute the contract")
+	}
+	if contract.ApprovedCount < contract.SignersCount {
+		return response(contract) // not yet
+	}
+
+	return executeContract(stub, cb, contract, kid)
+}
+
+// params[0] : contract ID
+// Only the last signer or the CCID created the contract can retry the execution failed contract.
+func contractRetryExecute(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, true)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		if _, ok := err.(NotExistedContractError); !ok {
+			return responseError(err, "failed to retry the contract execution")
+		}
+		// invoker is not a signer, it may be the CCID
+		if contract, err = cb.GetContractByID(id); err != nil {
+			return responseError(err, "failed to retry the contract execution")
+		}
+	}
+	// validate
+	if contract.CCID != ccid && contract.LastSigner != kid {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+	if err = contract.AssertExecutable(contract.ts); err != nil {
+		return responseError(err, "failed to retry the contract execution")
+	}
+	if nil == contract.FailedTime {
+		return responseCode(client.ErrorCodeNotFailed, "not failed contract")
+	}
+
+	return executeContract(stub, cb, contract, kid)
+}
+
+// params[0] : contract ID
+func contractGet(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, false)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		return responseError(err, "failed to get the contract")
+	}
+	if err = cb.AggregateContract(contract); err != nil {
+		return responseError(err, "failed to get the contract")
+	}
+
+	return response(contract)
+}
+
+// params[0] : contract ID
+func contractHistory(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, false)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	id := params[0]
+
+	cb := NewContractStub(stub)
+	contract, err := cb.GetContract(id, kid)
+	if err != nil {
+		return responseError(err, "failed to get the contract history")
+	}
+	history, err := cb.GetContractHistory(contract)
+	if err != nil {
+		return responseError(err, "failed to get the contract history")
+	}
+
+	return response(history)
+}
+
+// params[0] : ccid, comma separated CCIDs or '*' (all)
+// params[1] : option - 1 of [finished, unfinished, approved, unsigned, executed, canceled, disapproved, expired, all], default unsigned
+// params[2] : bookmark
+func contractList(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) < 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 2+")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, false)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	ccid := params[0]
+	option := "unsigned"
+	bookmark := ""
+	if len(params) > 1 {
+		option = params[1]
+		if len(params) > 2 {
+			bookmark = params[2]
+		}
+	}
+
+	cb := NewContractStub(stub)
+	res, err := cb.GetQueryContracts(kid, ParseCCIDs(ccid), option, bookmark)
+	if nil != err {
+		return responseError(err, "failed to get contracts list")
+	}
+
+	return response(res)
+}
+
+// helpers
+
+// executeContract invokes the execute callback of the contract approved by all signers.
+// If the callback fails, the approvals are kept and the contract is marked as execution failed, so it can be retried.
+func executeContract(stub shim.ChaincodeStubInterface, cb *ContractStub, contract *Contract, executor string) peer.Response {
+	result, err := invokeExecuteContract(stub, contract)
+	if result != nil {
+		contract.ExecuteResult = result
+	}
+	if err != nil {
+		if contract, err = cb.FailExecution(contract, executor, err.Error()); err != nil {
+			return responseError(err, "failed to execute the contract")
+		}
+		return response(contract)
+	}
+	if contract, err = cb.ExecuteContract(contract, executor); err != nil {
+		return responseError(err, "failed to execute the contract")
+	}
+	contract.Callback = result.Payload
+
+	return response(contract)
+}
+
+func invokeCallback(stub shim.ChaincodeStubInterface, target *CallbackTarget, args [][]byte) (*CallbackResult, error) {
+	ts, err := txtime.GetTime(stub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the timestamp")
+	}
+
+	res := stub.InvokeChaincode(target.Chaincode, args, target.Channel) // empty channel means the same channel
+
+	result := &CallbackResult{
+		Status: res.GetStatus(),
+		Time:   ts,
+	}
+	if res.GetStatus() == 200 {
+		result.Payload = base64.StdEncoding.EncodeToString(res.GetPayload())
+		return result, nil
+	}
+	result.Message = res.GetMessage()
+	return result, errors.New(res.GetMessage())
+}
+
+// if the approve callback is not registered, it returns nil result and nil error
+func invokeApproveContract(stub shim.ChaincodeStubInterface, contract *Contract) (*CallbackResult, error) {
+	target := contract.GetCallbackTarget()
+	if target.Approve == "" {
+		return nil, nil
+	}
+	// the approved count isn't passed, it's unknown without reading other signers' records (MVCC conflict)
+	args := [][]byte{[]byte(target.Approve), []byte(contract.DOCTYPEID), []byte(contract.Sign.Signer), []byte(contract.Document)}
+	return invokeCallback(stub, target, args)
+}
+
+func invokeExecuteContract(stub shim.ChaincodeStubInterface, contract *Contract) (*CallbackResult, error) {
+	target := contract.GetCallbackTarget()
+	args := [][]byte{[]byte(target.Execute), []byte(contract.DOCTYPEID), []byte(contract.Document)}
+	return invokeCallback(stub, target, args)
+}
+
+func invokeCancelContract(stub shim.ChaincodeStubInterface, contract *Contract) (*CallbackResult, error) {
+	target := contract.GetCallbackTarget()
+	args := [][]byte{[]byte(target.Cancel), []byte(contract.DOCTYPEID), []byte(contract.Document)}
+	return invokeCallback(stub, target, args)
+}
+
+// params[0] : selector over document fields (JSON object, ex. {"type": "transfer", "amount": {"$gte": 100}})
+// params[1] : bookmark
+// Invoked directly, it's scoped to the invoker (kid). Invoked by a chaincode, it's scoped to the chaincode (ccid).
+func contractSearch(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) < 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1+")
+	}
+
+	ccid, err := ccid.GetID(stub)
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidAccess, "invalid access")
+	}
+
+	signer := ""
+	if isDirect(ccid) {
+		// authentication
+		if signer, err = GetInvokerID(stub, false); err != nil {
+			return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+		}
+	}
+
+	selector, err := CreateDocumentSelector(params[0])
+	if err != nil {
+		return responseCode(client.ErrorCodeInvalidSelector, err.Error())
+	}
+	bookmark := ""
+	if len(params) > 1 {
+		bookmark = params[1]
+	}
+
+	cb := NewContractStub(stub)
+	res, err := cb.SearchContracts(signer, ccid, selector, bookmark)
+	if err != nil {
+		return responseError(err, "failed to search contracts")
+	}
+
+	return response(res)
+}
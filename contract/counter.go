@@ -0,0 +1,90 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+)
+
+// signer statuses counted by counters
+const (
+	SignerStatusUnsigned = "unsigned" // pending, not signed
+	SignerStatusApproved = "approved" // pending, approved
+	// executed, canceled, disapproved are same as contract statuses
+)
+
+// CounterObjectType is the composite key object type of counters, [signer, ccid]
+const CounterObjectType = "CNT"
+
+// Counts represents the number of contracts per signer status
+type Counts struct {
+	Unsigned    int `json:"unsigned"`
+	Approved    int `json:"approved"`
+	Executed    int `json:"executed"`
+	Canceled    int `json:"canceled"`
+	Disapproved int `json:"disapproved"`
+	Expired     int `json:"expired"` // moved by writes and counted by reads, unsigned and approved exclude it
+}
+
+// Add adds the delta to the count of the status
+func (c *Counts) Add(status string, delta int) {
+	switch status {
+	case SignerStatusUnsigned:
+		c.Unsigned += delta
+	case SignerStatusApproved:
+		c.Approved += delta
+	case ContractStatusExecuted:
+		c.Executed += delta
+	case ContractStatusCanceled:
+		c.Canceled += delta
+	case ContractStatusDisapproved:
+		c.Disapproved += delta
+	}
+}
+
+// Sum adds all counts of other
+func (c *Counts) Sum(other *Counts) {
+	c.Unsigned += other.Unsigned
+	c.Approved += other.Approved
+	c.Executed += other.Executed
+	c.Canceled += other.Canceled
+	c.Disapproved += other.Disapproved
+	c.Expired += other.Expired
+}
+
+// Counter is the state document of the signer's counts of the chaincode
+type Counter struct {
+	DOCTYPEID string `json:"@counter"` // signer
+	CCID      string `json:"ccid"`
+	Counts
+}
+
+// SignerStatus returns the signer status of the sign record
+// 1 of [unsigned, approved, executed, canceled, disapproved]
+func (r *SignRecord) SignerStatus() string {
+	if r.ExecutedTime != nil {
+		return ContractStatusExecuted
+	}
+	if r.CanceledTime != nil {
+		if r.Disapprover != "" {
+			return ContractStatusDisapproved
+		}
+		return ContractStatusCanceled
+	}
+	if r.Sign.ApprovedTime != nil {
+		return SignerStatusApproved
+	}
+	return SignerStatusUnsigned
+}
+
+// Summary is the result of 'summary'
+type Summary struct {
+	Signer string     `json:"signer"`
+	Counts            // total
+	CCIDs  []*Counter `json:"ccids"`
+}
+
+// MarshalPayload _
+func (s *Summary) MarshalPayload() ([]byte, error) {
+	return json.Marshal(s)
+}
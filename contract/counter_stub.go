@@ -0,0 +1,188 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/pkg/errors"
+)
+
+// CounterStub _
+type CounterStub struct {
+	stub     shim.ChaincodeStubInterface
+	counters map[string]*Counter // written in the transaction (writes aren't readable until commit)
+}
+
+// NewCounterStub _
+func NewCounterStub(stub shim.ChaincodeStubInterface) *CounterStub {
+	return &CounterStub{stub, map[string]*Counter{}}
+}
+
+// CreateKey _
+func (sb *CounterStub) CreateKey(signer, ccid string) (string, error) {
+	key, err := sb.stub.CreateCompositeKey(CounterObjectType, []string{signer, ccid})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the counter key")
+	}
+	return key, nil
+}
+
+// GetCounter returns the counter of the signer and the chaincode, zero counter if it's not stored
+func (sb *CounterStub) GetCounter(signer, ccid string) (*Counter, error) {
+	key, err := sb.CreateKey(signer, ccid)
+	if err != nil {
+		return nil, err
+	}
+	if counter, ok := sb.counters[key]; ok {
+		return counter, nil
+	}
+	data, err := sb.stub.GetState(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the counter state")
+	}
+	counter := &Counter{DOCTYPEID: signer, CCID: ccid}
+	if data != nil {
+		if err = json.Unmarshal(data, counter); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal the counter")
+		}
+	}
+	return counter, nil
+}
+
+// AddCount adds the delta to the count of the signer status.
+// Expired contracts of the counter are moved to the expired count by the first write of the transaction.
+func (sb *CounterStub) AddCount(signer, ccid, status string, delta int) error {
+	key, err := sb.CreateKey(signer, ccid)
+	if err != nil {
+		return err
+	}
+	_, written := sb.counters[key]
+	counter, err := sb.GetCounter(signer, ccid)
+	if err != nil {
+		return err
+	}
+	if !written {
+		if err = sb.moveExpired(counter); err != nil {
+			return err
+		}
+	}
+	counter.Add(status, delta)
+	data, err := json.Marshal(counter)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the counter")
+	}
+	if err = sb.stub.PutState(key, data); err != nil {
+		return errors.Wrap(err, "failed to put the counter state")
+	}
+	sb.counters[key] = counter
+	return nil
+}
+
+// GetCounters returns counters of all chaincodes of the signer
+func (sb *CounterStub) GetCounters(signer string) ([]*Counter, error) {
+	iter, err := sb.stub.GetStateByPartialCompositeKey(CounterObjectType, []string{signer})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get counters")
+	}
+	defer iter.Close()
+
+	counters := []*Counter{}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the counter")
+		}
+		counter := &Counter{}
+		if err = json.Unmarshal(kv.Value, counter); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal the counter")
+		}
+		counters = append(counters, counter)
+	}
+	return counters, nil
+}
+
+// CountExpired moves expired contracts from unsigned and approved counts to the expired count. (not stored)
+// Expiry isn't a transition, so they are counted by pending indexes. (expiry_time asc, only expired keys are read)
+// Expired contracts are moved out of pending indexes by writes of the counter (see moveExpired),
+// so it reads only contracts expired after the last write.
+func (sb *CounterStub) CountExpired(counter *Counter, ts *txtime.Time) error {
+	return sb.countExpired(counter, ts, false)
+}
+
+// moveExpired moves expired contracts to the expired count of the counter, and deletes their pending index keys.
+// Before the counters migration, pending indexes may be rewritten by the migration, so they aren't moved.
+func (sb *CounterStub) moveExpired(counter *Counter) error {
+	m, err := NewMigrationStub(sb.stub).GetMigration()
+	if err != nil {
+		return err
+	}
+	if m.SchemaVersion < SchemaVersionCounters {
+		return nil
+	}
+	ts, err := txtime.GetTime(sb.stub)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the timestamp")
+	}
+	return sb.countExpired(counter, ts, true)
+}
+
+func (sb *CounterStub) countExpired(counter *Counter, ts *txtime.Time, move bool) error {
+	now := AscTimeKey(ts)
+	for _, objectType := range []string{IndexUnsigned, IndexApproved} {
+		iter, err := sb.stub.GetStateByPartialCompositeKey(objectType, []string{counter.DOCTYPEID, counter.CCID})
+		if err != nil {
+			return errors.Wrap(err, "failed to get indexes")
+		}
+		expired := [][]string{}
+		for iter.HasNext() {
+			kv, err := iter.Next()
+			if err != nil {
+				iter.Close()
+				return errors.Wrap(err, "failed to get the index")
+			}
+			_, attrs, err := sb.stub.SplitCompositeKey(kv.Key)
+			if err != nil {
+				iter.Close()
+				return errors.Wrap(err, "failed to split the index key")
+			}
+			if len(attrs) != 4 || attrs[2] > now { // signer, ccid, time key, contract ID
+				break
+			}
+			expired = append(expired, attrs)
+		}
+		iter.Close()
+		if move {
+			for _, attrs := range expired {
+				if err = sb.deleteIndex(objectType, attrs); err != nil {
+					return err
+				}
+				// cross index : signer, time key, ccid, contract ID
+				if err = sb.deleteIndex(CrossIndex(objectType), []string{attrs[0], attrs[2], attrs[1], attrs[3]}); err != nil {
+					return err
+				}
+			}
+		}
+		count := len(expired)
+		if IndexUnsigned == objectType {
+			counter.Unsigned -= count
+		} else {
+			counter.Approved -= count
+		}
+		counter.Expired += count
+	}
+	return nil
+}
+
+func (sb *CounterStub) deleteIndex(objectType string, attrs []string) error {
+	key, err := sb.stub.CreateCompositeKey(objectType, attrs)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the index key")
+	}
+	if err = sb.stub.DelState(key); err != nil {
+		return errors.Wrap(err, "failed to delete the index")
+	}
+	return nil
+}
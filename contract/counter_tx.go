@@ -0,0 +1,55 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// params[0] : ccid, comma separated CCIDs or '*' (optional, default all)
+func contractSummary(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	// authentication
+	kid, err := GetInvokerID(stub, false)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	var ccids []string
+	if len(params) > 0 {
+		ccids = ParseCCIDs(params[0])
+	}
+
+	ts, err := txtime.GetTime(stub)
+	if err != nil {
+		return responseError(err, "failed to get the summary")
+	}
+
+	sb := NewCounterStub(stub)
+	counters := []*Counter{}
+	if nil == ccids {
+		if counters, err = sb.GetCounters(kid); err != nil {
+			return responseError(err, "failed to get the summary")
+		}
+	} else {
+		for _, ccid := range ccids {
+			counter, err := sb.GetCounter(kid, ccid)
+			if err != nil {
+				return responseError(err, "failed to get the summary")
+			}
+			counters = append(counters, counter)
+		}
+	}
+
+	summary := &Summary{Signer: kid, CCIDs: counters}
+	for _, counter := range counters {
+		if err = sb.CountExpired(counter, ts); err != nil {
+			return responseError(err, "failed to get the summary")
+		}
+		summary.Sum(&counter.Counts)
+	}
+
+	return response(summary)
+}
@@ -0,0 +1,70 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"fmt"
+
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// ResponsibleError is the interface used to distinguish responsible errors
+type ResponsibleError interface {
+	IsReponsible() bool
+}
+
+// ResponsibleErrorImpl _
+type ResponsibleErrorImpl struct{}
+
+// IsReponsible _
+func (e ResponsibleErrorImpl) IsReponsible() bool {
+	return true
+}
+
+// CodedError is the interface of responsible errors which have the error code
+type CodedError interface {
+	ResponsibleError
+	Code() client.ErrorCode
+}
+
+// ContractError is the responsible error of the error catalog
+type ContractError struct {
+	ResponsibleErrorImpl
+	code client.ErrorCode
+	msg  string
+}
+
+// NewContractError _
+func NewContractError(code client.ErrorCode, msg string) ContractError {
+	return ContractError{code: code, msg: msg}
+}
+
+// Error implements error interface
+func (e ContractError) Error() string {
+	return e.msg
+}
+
+// Code _
+func (e ContractError) Code() client.ErrorCode {
+	return e.code
+}
+
+// NotExistedContractError _
+type NotExistedContractError struct {
+	ResponsibleErrorImpl
+	id     string
+	signer string
+}
+
+// Error implements error interface
+func (e NotExistedContractError) Error() string {
+	if e.signer == "" {
+		return fmt.Sprintf("the contract [%s] is not exists", e.id)
+	}
+	return fmt.Sprintf("the contract [%s] for the signer [%s] is not exists", e.id, e.signer)
+}
+
+// Code _
+func (e NotExistedContractError) Code() client.ErrorCode {
+	return client.ErrorCodeContractNotFound
+}
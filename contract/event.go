@@ -0,0 +1,46 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+)
+
+// ContractEventName is the chaincode event name
+const ContractEventName = "kiesnet-contract"
+
+// contract statuses of events
+const (
+	ContractStatusCreated         = "created"
+	ContractStatusApproved        = "approved"
+	ContractStatusDisapproved     = "disapproved"
+	ContractStatusCanceled        = "canceled"
+	ContractStatusExecuted        = "executed"
+	ContractStatusExecutionFailed = "execution_failed"
+	ContractStatusCancelRequested = "cancel_requested"
+	ContractStatusFrozen          = "frozen"
+	ContractStatusUnfrozen        = "unfrozen"
+)
+
+// ContractEvent represents a state transition of the contract
+type ContractEvent struct {
+	ContractID string       `json:"contract_id"`
+	CCID       string       `json:"ccid"`
+	Actor      string       `json:"actor"`
+	Status     string       `json:"status"`
+	Signers    []string     `json:"signers"`
+	Time       *txtime.Time `json:"time"`
+}
+
+// ContractEvents aggregates all transitions of a transaction,
+// because Fabric allows only one event per transaction.
+type ContractEvents struct {
+	Events []*ContractEvent `json:"events"`
+}
+
+// MarshalPayload _
+func (e *ContractEvents) MarshalPayload() ([]byte, error) {
+	return json.Marshal(e)
+}
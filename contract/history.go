@@ -0,0 +1,233 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/pkg/errors"
+)
+
+// HistoryHeaderRecord is the record name of the contract header in history changes
+const HistoryHeaderRecord = "header"
+
+// HistoryChange represents a changed field of a record
+type HistoryChange struct {
+	Record string      `json:"record"` // 'header' or signer's KID
+	Field  string      `json:"field"`
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new,omitempty"`
+}
+
+// HistoryEntry represents changes of a transaction
+type HistoryEntry struct {
+	TxID    string           `json:"tx_id"`
+	Time    *txtime.Time     `json:"time"`
+	Actor   string           `json:"actor,omitempty"`
+	Changes []*HistoryChange `json:"changes"`
+}
+
+// ContractHistory _
+type ContractHistory struct {
+	ContractID string          `json:"contract_id"`
+	Entries    []*HistoryEntry `json:"entries"`
+}
+
+// MarshalPayload _
+func (h *ContractHistory) MarshalPayload() ([]byte, error) {
+	return json.Marshal(h)
+}
+
+// historyModification is a modification of a logical record (header or signer's sign)
+type historyModification struct {
+	record string
+	txID   string
+	time   *txtime.Time
+	fields map[string]interface{}
+}
+
+// GetContractHistory returns the change log of the contract from histories of all keys of the contract.
+// Index fields of sign records are not included, because they are copies of header fields.
+func (cb *ContractStub) GetContractHistory(contract *Contract) (*ContractHistory, error) {
+	signers := contract.signers
+	if nil == signers {
+		records, err := cb.GetSignRecords(contract)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			signers = append(signers, record.Sign.Signer)
+		}
+	}
+
+	mods := []*historyModification{}
+	// legacy states first, they are older than composite keys
+	legacyHeader, err := cb.getHistoryModifications(CreateLegacyHeaderKey(contract.DOCTYPEID), HistoryHeaderRecord, headerFields)
+	if err != nil {
+		return nil, err
+	}
+	mods = append(mods, legacyHeader...)
+	for _, signer := range signers {
+		if signer == contract.Creator && len(legacyHeader) == 0 {
+			// header fields of whole-copy legacy contracts are taken from the creator's copy
+			hm, err := cb.getHistoryModifications(CreateLegacyKey(contract.DOCTYPEID, signer), HistoryHeaderRecord, headerFields)
+			if err != nil {
+				return nil, err
+			}
+			mods = append(mods, hm...)
+		}
+		sm, err := cb.getHistoryModifications(CreateLegacyKey(contract.DOCTYPEID, signer), signer, signFields)
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, sm...)
+	}
+	key, err := cb.CreateHeaderKey(contract.DOCTYPEID)
+	if err != nil {
+		return nil, err
+	}
+	hm, err := cb.getHistoryModifications(key, HistoryHeaderRecord, headerFields)
+	if err != nil {
+		return nil, err
+	}
+	mods = append(mods, hm...)
+	for _, signer := range signers {
+		if key, err = cb.CreateKey(contract.DOCTYPEID, signer); err != nil {
+			return nil, err
+		}
+		sm, err := cb.getHistoryModifications(key, signer, signFields)
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, sm...)
+	}
+
+	sort.SliceStable(mods, func(i, j int) bool {
+		return mods[i].time.Cmp(mods[j].time) < 0
+	})
+
+	history := &ContractHistory{ContractID: contract.DOCTYPEID, Entries: []*HistoryEntry{}}
+	entries := map[string]*HistoryEntry{}
+	prevs := map[string]map[string]interface{}{} // previous fields by record
+	for _, mod := range mods {
+		entry := entries[mod.txID]
+		if nil == entry {
+			entry = &HistoryEntry{TxID: mod.txID, Time: mod.time, Changes: []*HistoryChange{}}
+			entries[mod.txID] = entry
+			history.Entries = append(history.Entries, entry)
+		}
+		prev := prevs[mod.record]
+		prevs[mod.record] = mod.fields
+		changes := diffFields(mod.record, prev, mod.fields)
+		entry.Changes = append(entry.Changes, changes...)
+		// actor
+		if mod.record == HistoryHeaderRecord {
+			if by, ok := mod.fields["updated_by"].(string); ok && entry.Actor == "" {
+				for _, c := range changes {
+					if c.Field == "updated_time" {
+						entry.Actor = by
+						break
+					}
+				}
+			}
+		} else {
+			for _, c := range changes {
+				if (c.Field == "approved_time" || c.Field == "disapproved_time" || c.Field == "cancel_requested_time") && c.New != nil {
+					entry.Actor = mod.record // signer's action
+				}
+			}
+		}
+	}
+
+	// remove transactions which changed nothing (ex. conversion of legacy contracts)
+	changed := history.Entries[:0]
+	for _, entry := range history.Entries {
+		if len(entry.Changes) > 0 {
+			changed = append(changed, entry)
+		}
+	}
+	history.Entries = changed
+
+	return history, nil
+}
+
+// getHistoryModifications returns modifications of the key (deletions are skipped)
+func (cb *ContractStub) getHistoryModifications(key, record string, fieldsFn func([]byte) (map[string]interface{}, error)) ([]*historyModification, error) {
+	iter, err := cb.stub.GetHistoryForKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the history")
+	}
+	defer iter.Close()
+
+	mods := []*historyModification{}
+	for iter.HasNext() {
+		km, err := iter.Next()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the history")
+		}
+		if km.GetIsDelete() {
+			continue
+		}
+		fields, err := fieldsFn(km.GetValue())
+		if err != nil {
+			return nil, err
+		}
+		ts := km.GetTimestamp()
+		mods = append(mods, &historyModification{
+			record: record,
+			txID:   km.GetTxId(),
+			time:   txtime.Unix(ts.GetSeconds(), int64(ts.GetNanos())),
+			fields: fields,
+		})
+	}
+	return mods, nil
+}
+
+// headerFields returns header fields of the header document or the whole-copy legacy contract
+func headerFields(data []byte) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the history value")
+	}
+	for _, k := range []string{"@contract", "@contract_header", "signers", "sign", "callback", "counted", "document_fields"} {
+		delete(fields, k)
+	}
+	return fields, nil
+}
+
+// signFields returns fields of the sign
+func signFields(data []byte) (map[string]interface{}, error) {
+	v := struct {
+		Sign map[string]interface{} `json:"sign"`
+	}{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the history value")
+	}
+	if nil == v.Sign {
+		v.Sign = map[string]interface{}{}
+	}
+	delete(v.Sign, "signer")
+	return v.Sign, nil
+}
+
+// diffFields returns changes from prev to next, sorted by field names
+func diffFields(record string, prev, next map[string]interface{}) []*HistoryChange {
+	names := map[string]bool{}
+	for k := range prev {
+		names[k] = true
+	}
+	for k := range next {
+		names[k] = true
+	}
+	changes := []*HistoryChange{}
+	for _, name := range sortedKeys(names) {
+		o, n := prev[name], next[name]
+		if !reflect.DeepEqual(o, n) {
+			changes = append(changes, &HistoryChange{Record: record, Field: name, Old: o, New: n})
+		}
+	}
+	return changes
+}
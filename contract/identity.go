@@ -0,0 +1,76 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/key-inside/kiesnet-ccpkg/kid"
+	"github.com/pkg/errors"
+)
+
+// IdentityProvider authenticates the invoker, and returns the ID of the invoker.
+// IDs are used as signers (KIDs) of contracts.
+type IdentityProvider interface {
+	// If secure is true, the provider requires the strong authentication. (ex. PIN of kiesnet-id)
+	GetID(stub shim.ChaincodeStubInterface, secure bool) (string, error)
+}
+
+// KIDProvider is the identity provider by the kiesnet-id chaincode
+type KIDProvider struct{}
+
+// GetID invokes the kiesnet-id chaincode
+func (p *KIDProvider) GetID(stub shim.ChaincodeStubInterface, secure bool) (string, error) {
+	return kid.GetID(stub, secure)
+}
+
+// CIDProvider is the identity provider by the client identity (X.509 certificate) of the proposal.
+// The ID is 'MSPID/value' of the attribute, or 'MSPID/CN' of the certificate if the attribute is empty.
+// IDs are prefixed by the MSP ID, so other organizations can't issue the same ID.
+type CIDProvider struct {
+	Attribute string
+}
+
+// GetID parses the certificate of the creator.
+// The proposal is signed by the certificate, so secure is ignored.
+func (p *CIDProvider) GetID(stub shim.ChaincodeStubInterface, secure bool) (string, error) {
+	msp, err := cid.GetMSPID(stub)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the MSP ID")
+	}
+	if p.Attribute != "" {
+		value, found, err := cid.GetAttributeValue(stub, p.Attribute)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get the attribute of the certificate")
+		}
+		if !found || value == "" {
+			return "", errors.Errorf("the certificate has no attribute: [%s]", p.Attribute)
+		}
+		return msp + "/" + value, nil
+	}
+	cert, err := cid.GetX509Certificate(stub)
+	if err != nil || nil == cert {
+		return "", errors.New("failed to get the certificate")
+	}
+	if cert.Subject.CommonName == "" {
+		return "", errors.New("the certificate has no common name")
+	}
+	return msp + "/" + cert.Subject.CommonName, nil
+}
+
+// NewIdentityProvider returns the identity provider of the config
+func NewIdentityProvider(cfg *Config) IdentityProvider {
+	if cfg.IdentityProvider == IdentityProviderCID {
+		return &CIDProvider{Attribute: cfg.IdentityAttribute}
+	}
+	return &KIDProvider{}
+}
+
+// GetInvokerID authenticates the invoker by the identity provider of the config
+func GetInvokerID(stub shim.ChaincodeStubInterface, secure bool) (string, error) {
+	cfg, err := NewConfigStub(stub).GetConfig()
+	if err != nil {
+		return "", err
+	}
+	return NewIdentityProvider(cfg).GetID(stub, secure)
+}
@@ -0,0 +1,96 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+)
+
+// object types of composite keys
+const (
+	ContractHeaderObjectType = "CTR" // [contract ID]
+	SignRecordObjectType     = "SGN" // [contract ID, signer]
+)
+
+// ContractListKeyPrefix is the prefix of simple keys listing contracts, CTL_{contract ID}.
+// Range queries don't accept composite keys, so batches over all contracts (migrations) resume from the contract ID by them.
+const ContractListKeyPrefix = "CTL_"
+
+// object types of composite key indexes of sign records
+// all of them are [signer, ccid, time key, contract ID]
+const (
+	IndexCreatedDesc  = "SIX_CT" // all, created_time desc
+	IndexFinishedDesc = "SIX_FD" // finished, finished_time desc
+	IndexFinishedAsc  = "SIX_FA" // unfinished, finished_time asc
+	IndexApproved     = "SIX_AP" // unfinished & approved, expiry_time asc (expired keys are moved by counters)
+	IndexUnsigned     = "SIX_US" // unfinished & unsigned, expiry_time asc (expired keys are moved by counters)
+	IndexExecuted     = "SIX_EX" // executed, executed_time desc
+	IndexCanceled     = "SIX_CN" // canceled (not disapproved), canceled_time desc
+	IndexDisapproved  = "SIX_DA" // disapproved, canceled_time desc
+	IndexExpiryDesc   = "SIX_XD" // neither executed nor canceled, expiry_time desc (expired)
+)
+
+// CrossIndexPrefix is the object type prefix of indexes across chaincodes.
+// Each index of sign records has the cross index, [signer, time key, ccid, contract ID]. (ex. SIX_CT -> SCX_CT)
+const CrossIndexPrefix = "SCX_"
+
+// CrossIndex returns the object type of the cross index
+func CrossIndex(objectType string) string {
+	return CrossIndexPrefix + strings.TrimPrefix(objectType, "SIX_")
+}
+
+// IndexValue is the value of index keys (empty value means deletion)
+var IndexValue = []byte{0x00}
+
+// AscTimeKey returns the time key which is sorted in ascending order
+func AscTimeKey(t *txtime.Time) string {
+	return fmt.Sprintf("%019d", t.UnixNano())
+}
+
+// DescTimeKey returns the time key which is sorted in descending order
+func DescTimeKey(t *txtime.Time) string {
+	return fmt.Sprintf("%019d", math.MaxInt64-t.UnixNano())
+}
+
+// IndexAttributes returns attributes of all indexes (and cross indexes) of the sign record, mapped by object types
+func (r *SignRecord) IndexAttributes() map[string][]string {
+	attrs := map[string][]string{}
+	signer := r.Sign.Signer
+	if r.CreatedTime != nil {
+		attrs[IndexCreatedDesc] = []string{signer, r.CCID, DescTimeKey(r.CreatedTime), r.DOCTYPEID}
+	}
+	if r.FinishedTime != nil {
+		attrs[IndexFinishedDesc] = []string{signer, r.CCID, DescTimeKey(r.FinishedTime), r.DOCTYPEID}
+		attrs[IndexFinishedAsc] = []string{signer, r.CCID, AscTimeKey(r.FinishedTime), r.DOCTYPEID}
+	}
+	if r.ExecutedTime != nil {
+		attrs[IndexExecuted] = []string{signer, r.CCID, DescTimeKey(r.ExecutedTime), r.DOCTYPEID}
+	}
+	if r.CanceledTime != nil {
+		if r.Disapprover != "" {
+			attrs[IndexDisapproved] = []string{signer, r.CCID, DescTimeKey(r.CanceledTime), r.DOCTYPEID}
+		} else {
+			attrs[IndexCanceled] = []string{signer, r.CCID, DescTimeKey(r.CanceledTime), r.DOCTYPEID}
+		}
+	}
+	if r.ExecutedTime == nil && r.CanceledTime == nil && r.ExpiryTime != nil {
+		attrs[IndexExpiryDesc] = []string{signer, r.CCID, DescTimeKey(r.ExpiryTime), r.DOCTYPEID}
+		if r.Sign.ApprovedTime != nil {
+			attrs[IndexApproved] = []string{signer, r.CCID, AscTimeKey(r.ExpiryTime), r.DOCTYPEID}
+		} else if r.Sign.DisapprovedTime == nil {
+			attrs[IndexUnsigned] = []string{signer, r.CCID, AscTimeKey(r.ExpiryTime), r.DOCTYPEID}
+		}
+	}
+	cross := make(map[string][]string, len(attrs))
+	for objectType, a := range attrs {
+		cross[CrossIndex(objectType)] = []string{a[0], a[2], a[1], a[3]}
+	}
+	for objectType, a := range cross {
+		attrs[objectType] = a
+	}
+	return attrs
+}
@@ -0,0 +1,173 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Legacy layouts use simple keys.
+// - CTR_{id}_{signer} : whole contract per signer, or sign record (with CTR_{id} header)
+// Legacy contracts are converted to composite keys when they are updated.
+
+// CreateLegacyKey _
+func CreateLegacyKey(id, signer string) string {
+	return fmt.Sprintf("CTR_%s_%s", id, signer)
+}
+
+// CreateLegacyHeaderKey _
+func CreateLegacyHeaderKey(id string) string {
+	return "CTR_" + id
+}
+
+// CreateLegacyKeyRange returns the key range of all legacy signers' states of the contract
+func CreateLegacyKeyRange(id string) (string, string) {
+	prefix := CreateLegacyKey(id, "")
+	return prefix, prefix + string(utf8.MaxRune)
+}
+
+// LegacyKeyPrefix _
+const LegacyKeyPrefix = "CTR_"
+
+// parseLegacyKey returns the contract ID of the legacy key (CTR_{id} or CTR_{id}_{signer})
+func parseLegacyKey(key string) string {
+	id := strings.TrimPrefix(key, LegacyKeyPrefix)
+	if i := strings.Index(id, "_"); i >= 0 {
+		id = id[:i]
+	}
+	return id
+}
+
+// MigrateLegacyContracts converts legacy contracts to composite keys, at most 'size' contracts from the start key.
+// It returns the start key of the next batch (empty means done), and the number of converted contracts.
+func (cb *ContractStub) MigrateLegacyContracts(start string, size int) (string, int, error) {
+	if "" == start {
+		start = LegacyKeyPrefix
+	}
+	iter, err := cb.stub.GetStateByRange(start, LegacyKeyPrefix+string(utf8.MaxRune))
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to get legacy contracts")
+	}
+	defer iter.Close()
+
+	count := 0
+	last := ""
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return "", 0, errors.Wrap(err, "failed to get the legacy contract")
+		}
+		id := parseLegacyKey(kv.Key)
+		if id == last { // keys of the contract are contiguous
+			continue
+		}
+		if count >= size {
+			return kv.Key, count, nil
+		}
+		last = id
+		if err = cb.migrateLegacyContract(id); err != nil {
+			return "", 0, err
+		}
+		count++
+	}
+	return "", count, nil
+}
+
+// migrateLegacyContract converts the legacy contract, the header without signers' states is deleted
+func (cb *ContractStub) migrateLegacyContract(id string) error {
+	data, err := cb.getFirstValue(cb.stub.GetStateByRange(CreateLegacyKeyRange(id)))
+	if err != nil {
+		return err
+	}
+	if nil == data { // orphan header
+		if err = cb.stub.DelState(CreateLegacyHeaderKey(id)); err != nil {
+			return errors.Wrap(err, "failed to delete the legacy contract header")
+		}
+		return nil
+	}
+	contract, err := cb.UnmarshalContract(data)
+	if err != nil {
+		return err
+	}
+	if !contract.legacy {
+		return nil
+	}
+	return cb.UpdateContracts(contract)
+}
+
+// UnmarshalLegacyContract assembles the legacy contract
+func (cb *ContractStub) UnmarshalLegacyContract(data []byte, record *SignRecord) (*Contract, error) {
+	hdata, err := cb.stub.GetState(CreateLegacyHeaderKey(record.DOCTYPEID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the contract header state")
+	}
+	if hdata != nil {
+		doc := &ContractHeaderDoc{}
+		if err = json.Unmarshal(hdata, doc); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal the contract header")
+		}
+		return &Contract{
+			DOCTYPEID:      record.DOCTYPEID,
+			ContractHeader: doc.ContractHeader,
+			Sign:           record.Sign,
+			signers:        doc.Signers,
+			legacy:         true,
+		}, nil
+	}
+	// whole contract
+	contract := &Contract{}
+	if err = json.Unmarshal(data, contract); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the contract")
+	}
+	contract.legacy = true
+	return contract, nil
+}
+
+// GetLegacySignRecords returns all legacy signers' states of the contract as sign records
+func (cb *ContractStub) GetLegacySignRecords(id string) ([]*SignRecord, error) {
+	iter, err := cb.stub.GetStateByRange(CreateLegacyKeyRange(id))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get legacy contracts")
+	}
+	defer iter.Close()
+
+	records := []*SignRecord{}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the legacy contract")
+		}
+		record := &SignRecord{}
+		if err = json.Unmarshal(kv.Value, record); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal the legacy contract")
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// DeleteLegacyContract deletes all legacy states of the contract.
+// If the contract doesn't know its signers, they are taken from the records.
+func (cb *ContractStub) DeleteLegacyContract(contract *Contract, records []*SignRecord) error {
+	signers := make([]string, 0, len(records))
+	for _, record := range records {
+		if err := cb.stub.DelState(CreateLegacyKey(contract.DOCTYPEID, record.Sign.Signer)); err != nil {
+			return errors.Wrap(err, "failed to delete the legacy contract")
+		}
+		signers = append(signers, record.Sign.Signer)
+	}
+	if err := cb.stub.DelState(CreateLegacyHeaderKey(contract.DOCTYPEID)); err != nil {
+		return errors.Wrap(err, "failed to delete the legacy contract header")
+	}
+	if nil == contract.signers {
+		sort.Strings(signers)
+		contract.signers = signers
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+)
+
+// Version is the chaincode version
+const Version = "1.4"
+
+// schema versions of the contract state
+// 1 : simple keys (CTR_{id}_{signer}, legacy)
+// 2 : composite keys (CTR header + SGN sign records + SIX_* indexes)
+// 3 : disapprover and status indexes (SIX_EX, SIX_CN, SIX_DA, SIX_XD)
+// 4 : cross indexes across chaincodes (SCX_*)
+// 5 : counters of signers (CNT)
+// 6 : document fields of contract headers (search)
+const (
+	SchemaVersionLegacy         = 1
+	SchemaVersionComposite      = 2
+	SchemaVersionStatusIndexes  = 3
+	SchemaVersionCrossIndexes   = 4
+	SchemaVersionCounters       = 5
+	SchemaVersionDocumentFields = 6
+	SchemaVersion               = SchemaVersionDocumentFields // latest
+)
+
+// Migration is the schema version and the progress of the migration
+type Migration struct {
+	DOCTYPEID     string       `json:"@migration"`
+	SchemaVersion int          `json:"schema_version"`         // migrated version
+	Target        int          `json:"target,omitempty"`       // migrating version
+	Bookmark      string       `json:"bookmark,omitempty"`     // inclusive start of the next batch, legacy key or contract ID
+	Migrated      int          `json:"migrated"`               // number of migrated contracts
+	StartedTime   *txtime.Time `json:"started_time,omitempty"` // of the migrating version
+	UpdatedTime   *txtime.Time `json:"updated_time,omitempty"`
+	FinishedTime  *txtime.Time `json:"finished_time,omitempty"` // of the migrated version
+	UpdatedBy     string       `json:"updated_by,omitempty"`
+}
+
+// NewMigration returns the initial migration state.
+// Stored contracts may be legacy, if the schema version isn't stored.
+func NewMigration() *Migration {
+	return &Migration{
+		DOCTYPEID:     "migration",
+		SchemaVersion: SchemaVersionLegacy,
+	}
+}
+
+// IsDone _
+func (m *Migration) IsDone() bool {
+	return m.SchemaVersion >= SchemaVersion
+}
+
+// MarshalPayload _
+func (m *Migration) MarshalPayload() ([]byte, error) {
+	return json.Marshal(m)
+}
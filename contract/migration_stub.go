@@ -0,0 +1,89 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/pkg/errors"
+)
+
+// MigrationKey is the state key of the migration
+const MigrationKey = "MGR"
+
+// MigrationStub _
+type MigrationStub struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewMigrationStub _
+func NewMigrationStub(stub shim.ChaincodeStubInterface) *MigrationStub {
+	return &MigrationStub{stub}
+}
+
+// GetMigration returns the migration state, or the initial state if it's not stored
+func (sb *MigrationStub) GetMigration() (*Migration, error) {
+	data, err := sb.stub.GetState(MigrationKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the migration state")
+	}
+	m := NewMigration()
+	if data != nil {
+		if err = json.Unmarshal(data, m); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal the migration")
+		}
+	}
+	return m, nil
+}
+
+// PutMigration _
+func (sb *MigrationStub) PutMigration(m *Migration) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the migration")
+	}
+	if err = sb.stub.PutState(MigrationKey, data); err != nil {
+		return errors.Wrap(err, "failed to put the migration state")
+	}
+	return nil
+}
+
+// InitMigration stores the latest schema version on a new deployment (no state),
+// so new networks don't need migrations. Existing states are kept. (Init)
+func (sb *MigrationStub) InitMigration() error {
+	data, err := sb.stub.GetState(MigrationKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the migration state")
+	}
+	if data != nil {
+		return nil
+	}
+	empty, err := sb.hasNoContracts()
+	if err != nil || !empty {
+		return err
+	}
+	m := NewMigration()
+	m.SchemaVersion = SchemaVersion
+	return sb.PutMigration(m)
+}
+
+// hasNoContracts reports whether neither legacy nor composite contracts are stored
+func (sb *MigrationStub) hasNoContracts() (bool, error) {
+	iter, err := sb.stub.GetStateByRange(LegacyKeyPrefix, LegacyKeyPrefix+string(utf8.MaxRune))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get legacy contracts")
+	}
+	found := iter.HasNext()
+	iter.Close()
+	if found {
+		return false, nil
+	}
+	iter, err = sb.stub.GetStateByPartialCompositeKey(ContractHeaderObjectType, []string{})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get contracts")
+	}
+	defer iter.Close()
+	return !iter.HasNext(), nil
+}
@@ -0,0 +1,95 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// params[0] : batch size, number of contracts migrated by the transaction (optional, default 'fetch_size')
+// It migrates one schema version at a time, and resumes from the bookmark of the last batch.
+// Invoke it repeatedly until 'schema_version' is the latest.
+func migrate(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	// authentication
+	admin, err := GetAdmin(stub)
+	if err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	cb := NewContractStub(stub)
+	cfg, err := cb.GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+	size := int(cfg.FetchSize)
+	if len(params) > 0 {
+		if size, err = strconv.Atoi(params[0]); err != nil || size < 1 {
+			return responseCode(client.ErrorCodeInvalidParameters, "invalid batch size")
+		}
+	}
+
+	ts, err := txtime.GetTime(stub)
+	if err != nil {
+		return responseError(err, "failed to migrate")
+	}
+
+	sb := NewMigrationStub(stub)
+	m, err := sb.GetMigration()
+	if err != nil {
+		return responseError(err, "failed to migrate")
+	}
+	if m.IsDone() {
+		return responseCode(client.ErrorCodeAlreadyMigrated, "already migrated")
+	}
+	if m.Target != m.SchemaVersion+1 { // start the next step
+		m.Target = m.SchemaVersion + 1
+		m.Bookmark = ""
+		m.Migrated = 0
+		m.StartedTime = ts
+		m.FinishedTime = nil
+	}
+
+	next, count := "", 0
+	switch m.Target {
+	case SchemaVersionComposite: // legacy -> composite
+		next, count, err = cb.MigrateLegacyContracts(m.Bookmark, size)
+	case SchemaVersionStatusIndexes, SchemaVersionCrossIndexes:
+		next, count, err = cb.ReindexContracts(m.Bookmark, size)
+	case SchemaVersionCounters:
+		next, count, err = cb.CountContracts(m.Bookmark, size)
+	case SchemaVersionDocumentFields:
+		next, count, err = cb.RewriteContractHeaders(m.Bookmark, size)
+	}
+	if err != nil {
+		return responseError(err, "failed to migrate")
+	}
+	m.Bookmark = next
+	m.Migrated += count
+	m.UpdatedTime = ts
+	m.UpdatedBy = admin
+	if "" == next { // done
+		m.SchemaVersion = m.Target
+		m.Target = 0
+		m.FinishedTime = ts
+	}
+	if err = sb.PutMigration(m); err != nil {
+		return responseError(err, "failed to migrate")
+	}
+
+	return response(m)
+}
+
+// migration status
+func migrateStatus(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	m, err := NewMigrationStub(stub).GetMigration()
+	if err != nil {
+		return responseError(err, "failed to get the migration")
+	}
+
+	return response(m)
+}
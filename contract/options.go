@@ -0,0 +1,36 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ContractOptions represents options of the contract creation
+type ContractOptions struct {
+	Expiry       int64         `json:"expiry"` // seconds
+	CancelPolicy *CancelPolicy `json:"cancel_policy,omitempty"`
+	// callback target of the contract, it overrides the target registered by 'callback/set'
+	CallbackTarget *CallbackTarget `json:"callback_target,omitempty"`
+}
+
+// ParseContractOptions parses the expiry parameter of 'create'.
+// It's the expiry (int64 seconds) or options JSON object.
+func ParseContractOptions(param string) (*ContractOptions, error) {
+	opts := &ContractOptions{}
+	if strings.HasPrefix(strings.TrimSpace(param), "{") {
+		if err := json.Unmarshal([]byte(param), opts); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal options")
+		}
+		return opts, nil
+	}
+	expiry, err := strconv.ParseInt(param, 10, 64)
+	if err == nil {
+		opts.Expiry = expiry
+	}
+	return opts, nil
+}
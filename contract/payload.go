@@ -1,6 +1,6 @@
 // Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
 
-package main
+package contract
 
 // Payload _
 type Payload interface {
@@ -0,0 +1,85 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+	"github.com/pkg/errors"
+)
+
+// PublicKeyKeyPrefix _
+const PublicKeyKeyPrefix = "CPK_"
+
+// PublicKeyStub _
+type PublicKeyStub struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewPublicKeyStub _
+func NewPublicKeyStub(stub shim.ChaincodeStubInterface) *PublicKeyStub {
+	return &PublicKeyStub{stub}
+}
+
+// CreateKey _
+func (kb *PublicKeyStub) CreateKey(kid string) string {
+	return PublicKeyKeyPrefix + kid
+}
+
+// GetPublicKey returns the public key of the signer, or nil if it's not registered
+func (kb *PublicKeyStub) GetPublicKey(kid string) (*PublicKey, error) {
+	data, err := kb.stub.GetState(kb.CreateKey(kid))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the public key state")
+	}
+	if nil == data {
+		return nil, nil
+	}
+	key := &PublicKey{}
+	if err = json.Unmarshal(data, key); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the public key")
+	}
+	return key, nil
+}
+
+// GetSignerPublicKey returns the public key of the signer, or an error if it's not registered
+func (kb *PublicKeyStub) GetSignerPublicKey(kid string) (*PublicKey, error) {
+	key, err := kb.GetPublicKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	if nil == key {
+		return nil, NewContractError(client.ErrorCodePublicKeyNotFound, "public key not found: ["+kid+"]")
+	}
+	return key, nil
+}
+
+// PutPublicKey registers or replaces the public key of the signer.
+// Signatures stored on signs have their own public keys, so replacing doesn't affect them.
+func (kb *PublicKeyStub) PutPublicKey(key *PublicKey) error {
+	ts, err := txtime.GetTime(kb.stub)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the timestamp")
+	}
+	prev, err := kb.GetPublicKey(key.DOCTYPEID)
+	if err != nil {
+		return err
+	}
+	if prev != nil {
+		key.RegisteredTime = prev.RegisteredTime
+	} else {
+		key.RegisteredTime = ts
+	}
+	key.UpdatedTime = ts
+	data, err := json.Marshal(key)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the public key")
+	}
+	if err = kb.stub.PutState(kb.CreateKey(key.DOCTYPEID), data); err != nil {
+		return errors.Wrap(err, "failed to put the public key state")
+	}
+	return nil
+}
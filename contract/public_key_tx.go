@@ -0,0 +1,53 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// params[0] : KID (optional, default the invoker)
+func publicKeyGet(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	var kid string
+	if len(params) > 0 && params[0] != "" {
+		kid = params[0]
+	} else {
+		// authentication
+		var err error
+		if kid, err = GetInvokerID(stub, false); err != nil {
+			return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+		}
+	}
+
+	key, err := NewPublicKeyStub(stub).GetSignerPublicKey(kid)
+	if err != nil {
+		return responseError(err, "failed to get the public key")
+	}
+
+	return response(key)
+}
+
+// params[0] : public key (PEM or base64 encoded PKIX, ECDSA or Ed25519)
+func publicKeyRegister(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	// authentication
+	kid, err := GetInvokerID(stub, true)
+	if err != nil {
+		return responseCode(client.ErrorCodeAuthenticationFailed, err.Error())
+	}
+
+	key, err := NewPublicKey(kid, params[0])
+	if err != nil {
+		return responseError(err, "failed to register the public key")
+	}
+	if err = NewPublicKeyStub(stub).PutPublicKey(key); err != nil {
+		return responseError(err, "failed to register the public key")
+	}
+
+	return response(key)
+}
@@ -0,0 +1,623 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+)
+
+// QueryContractHeadersFrom _
+const QueryContractHeadersFrom = `{
+	"selector": {
+		"@contract_header": {
+			"$gte": "%s"
+		}
+	},
+	"sort": [{"@contract_header": "asc"}],
+	"use_index": ["contract", "header-id"]
+}`
+
+// CreateQueryContractHeadersFrom _
+func CreateQueryContractHeadersFrom(id string) string {
+	return fmt.Sprintf(QueryContractHeadersFrom, id)
+}
+
+// QueryContractsBySigner _
+const QueryContractsBySigner = `{
+	"selector": {
+		"@contract": {
+			"$exists": true
+		},
+		"sign.signer": "%s",
+		"ccid": "%s"
+	},
+	"sort": [{"sign.signer": "desc"}, {"ccid": "desc"}, {"created_time": "desc"}],
+	"use_index": ["contract", "created-time"]
+}`
+
+// CreateQueryContractsBySigner _
+func CreateQueryContractsBySigner(kid, ccid string) string {
+	return fmt.Sprintf(QueryContractsBySigner, kid, ccid)
+}
+
+// QueryFinishedContractsBySigner _
+const QueryFinishedContractsBySigner = `{
+	"selector": {
+		"@contract": {
+			"$exists": true
+		},
+		"sign.signer": "%s",
+		"ccid": "%s",
+		"finished_time": {
+			"$lte": "%s"
+		}
+	},
+	"sort": [{"sign.signer": "desc"}, {"ccid": "desc"}, {"finished_time": "desc"}],
+	"use_index": ["contract", "finished-time"]
+}`
+
+// CreateQueryFinishedContractsBySigner _
+func CreateQueryFinishedContractsBySigner(kid, ccid string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryFinishedContractsBySigner, kid, ccid, ts.String())
+}
+
+// QueryUnfinishedContractsBySigner _
+const QueryUnfinishedContractsBySigner = `{
+	"selector": {
+		"@contract": {
+			"$exists": true
+		},
+		"sign.signer": "%s",
+		"ccid": "%s",
+		"finished_time": {
+			"$gt": "%s"
+		}
+	},
+	"sort": ["sign.signer", "ccid", "finished_time"],
+	"use_index": ["contract", "finished-time"]
+}`
+
+// CreateQueryUnfinishedContractsBySigner _
+func CreateQueryUnfinishedContractsBySigner(kid, ccid string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryUnfinishedContractsBySigner, kid, ccid, ts.String())
+}
+
+// QueryApprovedContractsBySigner - unfinished, approved
+const QueryApprovedContractsBySigner = `{
+	"selector": {
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"sign.approved_time": {
+					"$exists": true
+				}
+			},
+			{
+				"executed_time": {
+					"$exists": false
+				}
+			},
+			{
+				"canceled_time": {
+					"$exists": false
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"ccid": "%s",
+		"expiry_time": {
+			"$gt": "%s"
+		}
+	},
+	"sort": ["sign.signer", "ccid", "expiry_time"],
+	"use_index": ["contract", "approved-expiry-time"]
+}`
+
+// CreateQueryApprovedContractsBySigner _
+func CreateQueryApprovedContractsBySigner(kid, ccid string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryApprovedContractsBySigner, kid, ccid, ts.String())
+}
+
+// QueryUnsignedContractsBySigner - unfinished, unsigned
+const QueryUnsignedContractsBySigner = `{
+	"selector": {
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"sign.approved_time": {
+					"$exists": false
+				}
+			},
+			{
+				"sign.disapproved_time": {
+					"$exists": false
+				}
+			},
+			{
+				"executed_time": {
+					"$exists": false
+				}
+			},
+			{
+				"canceled_time": {
+					"$exists": false
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"ccid": "%s",
+		"expiry_time": {
+			"$gt": "%s"
+		}
+	},
+	"sort": ["sign.signer", "ccid", "expiry_time"],
+	"use_index": ["contract", "unsigned-expiry-time"]
+}`
+
+// CreateQueryUnsignedContractsBySigner _
+func CreateQueryUnsignedContractsBySigner(kid, ccid string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryUnsignedContractsBySigner, kid, ccid, ts.String())
+}
+
+// QueryExecutedContractsBySigner _
+const QueryExecutedContractsBySigner = `{
+	"selector": {
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"executed_time": {
+					"$exists": true
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"ccid": "%s"
+	},
+	"sort": [{"sign.signer": "desc"}, {"ccid": "desc"}, {"executed_time": "desc"}],
+	"use_index": ["contract", "executed-time"]
+}`
+
+// CreateQueryExecutedContractsBySigner _
+func CreateQueryExecutedContractsBySigner(kid, ccid string) string {
+	return fmt.Sprintf(QueryExecutedContractsBySigner, kid, ccid)
+}
+
+// QueryCanceledContractsBySigner - canceled, not disapproved
+const QueryCanceledContractsBySigner = `{
+	"selector": {
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"canceled_time": {
+					"$exists": true
+				}
+			},
+			{
+				"disapprover": {
+					"$exists": false
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"ccid": "%s"
+	},
+	"sort": [{"sign.signer": "desc"}, {"ccid": "desc"}, {"canceled_time": "desc"}],
+	"use_index": ["contract", "canceled-time"]
+}`
+
+// CreateQueryCanceledContractsBySigner _
+func CreateQueryCanceledContractsBySigner(kid, ccid string) string {
+	return fmt.Sprintf(QueryCanceledContractsBySigner, kid, ccid)
+}
+
+// QueryDisapprovedContractsBySigner _
+const QueryDisapprovedContractsBySigner = `{
+	"selector": {
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"disapprover": {
+					"$exists": true
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"ccid": "%s"
+	},
+	"sort": [{"sign.signer": "desc"}, {"ccid": "desc"}, {"canceled_time": "desc"}],
+	"use_index": ["contract", "disapproved-time"]
+}`
+
+// CreateQueryDisapprovedContractsBySigner _
+func CreateQueryDisapprovedContractsBySigner(kid, ccid string) string {
+	return fmt.Sprintf(QueryDisapprovedContractsBySigner, kid, ccid)
+}
+
+// QueryExpiredContractsBySigner - neither executed nor canceled until the expiry time
+const QueryExpiredContractsBySigner = `{
+	"selector": {
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"executed_time": {
+					"$exists": false
+				}
+			},
+			{
+				"canceled_time": {
+					"$exists": false
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"ccid": "%s",
+		"expiry_time": {
+			"$lte": "%s"
+		}
+	},
+	"sort": [{"sign.signer": "desc"}, {"ccid": "desc"}, {"expiry_time": "desc"}],
+	"use_index": ["contract", "expired-expiry-time"]
+}`
+
+// CreateQueryExpiredContractsBySigner _
+func CreateQueryExpiredContractsBySigner(kid, ccid string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryExpiredContractsBySigner, kid, ccid, ts.String())
+}
+
+// ParseCCIDs parses the ccid parameter of 'list'.
+// '*' or empty means all (nil), or comma separated CCIDs.
+func ParseCCIDs(param string) []string {
+	if "*" == param {
+		return nil
+	}
+	ccids := splitList(param)
+	if len(ccids) == 0 {
+		return nil
+	}
+	return ccids
+}
+
+// Queries across chaincodes (all or a set of CCIDs) have the CCIDs condition as the first selector field.
+// It's empty (all) or '"ccid": {"$in": [...]},' (see CreateCCIDsCondition)
+
+// CreateCCIDsCondition returns the selector field of CCIDs, empty (nil) means all
+func CreateCCIDsCondition(ccids []string) string {
+	if len(ccids) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(ccids) // strings can't fail
+	return fmt.Sprintf("\n\t\t\"ccid\": {\"$in\": %s},", data)
+}
+
+// QueryContractsBySignerCCIDs _
+const QueryContractsBySignerCCIDs = `{
+	"selector": {%s
+		"@contract": {
+			"$exists": true
+		},
+		"sign.signer": "%s"
+	},
+	"sort": [{"sign.signer": "desc"}, {"created_time": "desc"}],
+	"use_index": ["contract", "signer-created-time"]
+}`
+
+// CreateQueryContractsBySignerCCIDs _
+func CreateQueryContractsBySignerCCIDs(kid string, ccids []string) string {
+	return fmt.Sprintf(QueryContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid)
+}
+
+// QueryFinishedContractsBySignerCCIDs _
+const QueryFinishedContractsBySignerCCIDs = `{
+	"selector": {%s
+		"@contract": {
+			"$exists": true
+		},
+		"sign.signer": "%s",
+		"finished_time": {
+			"$lte": "%s"
+		}
+	},
+	"sort": [{"sign.signer": "desc"}, {"finished_time": "desc"}],
+	"use_index": ["contract", "signer-finished-time"]
+}`
+
+// CreateQueryFinishedContractsBySignerCCIDs _
+func CreateQueryFinishedContractsBySignerCCIDs(kid string, ccids []string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryFinishedContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid, ts.String())
+}
+
+// QueryUnfinishedContractsBySignerCCIDs _
+const QueryUnfinishedContractsBySignerCCIDs = `{
+	"selector": {%s
+		"@contract": {
+			"$exists": true
+		},
+		"sign.signer": "%s",
+		"finished_time": {
+			"$gt": "%s"
+		}
+	},
+	"sort": ["sign.signer", "finished_time"],
+	"use_index": ["contract", "signer-finished-time"]
+}`
+
+// CreateQueryUnfinishedContractsBySignerCCIDs _
+func CreateQueryUnfinishedContractsBySignerCCIDs(kid string, ccids []string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryUnfinishedContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid, ts.String())
+}
+
+// QueryApprovedContractsBySignerCCIDs - unfinished, approved
+const QueryApprovedContractsBySignerCCIDs = `{
+	"selector": {%s
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"sign.approved_time": {
+					"$exists": true
+				}
+			},
+			{
+				"executed_time": {
+					"$exists": false
+				}
+			},
+			{
+				"canceled_time": {
+					"$exists": false
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"expiry_time": {
+			"$gt": "%s"
+		}
+	},
+	"sort": ["sign.signer", "expiry_time"],
+	"use_index": ["contract", "signer-approved-expiry-time"]
+}`
+
+// CreateQueryApprovedContractsBySignerCCIDs _
+func CreateQueryApprovedContractsBySignerCCIDs(kid string, ccids []string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryApprovedContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid, ts.String())
+}
+
+// QueryUnsignedContractsBySignerCCIDs - unfinished, unsigned
+const QueryUnsignedContractsBySignerCCIDs = `{
+	"selector": {%s
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"sign.approved_time": {
+					"$exists": false
+				}
+			},
+			{
+				"sign.disapproved_time": {
+					"$exists": false
+				}
+			},
+			{
+				"executed_time": {
+					"$exists": false
+				}
+			},
+			{
+				"canceled_time": {
+					"$exists": false
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"expiry_time": {
+			"$gt": "%s"
+		}
+	},
+	"sort": ["sign.signer", "expiry_time"],
+	"use_index": ["contract", "signer-unsigned-expiry-time"]
+}`
+
+// CreateQueryUnsignedContractsBySignerCCIDs _
+func CreateQueryUnsignedContractsBySignerCCIDs(kid string, ccids []string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryUnsignedContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid, ts.String())
+}
+
+// QueryExecutedContractsBySignerCCIDs _
+const QueryExecutedContractsBySignerCCIDs = `{
+	"selector": {%s
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"executed_time": {
+					"$exists": true
+				}
+			}
+		],
+		"sign.signer": "%s"
+	},
+	"sort": [{"sign.signer": "desc"}, {"executed_time": "desc"}],
+	"use_index": ["contract", "signer-executed-time"]
+}`
+
+// CreateQueryExecutedContractsBySignerCCIDs _
+func CreateQueryExecutedContractsBySignerCCIDs(kid string, ccids []string) string {
+	return fmt.Sprintf(QueryExecutedContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid)
+}
+
+// QueryCanceledContractsBySignerCCIDs - canceled, not disapproved
+const QueryCanceledContractsBySignerCCIDs = `{
+	"selector": {%s
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"canceled_time": {
+					"$exists": true
+				}
+			},
+			{
+				"disapprover": {
+					"$exists": false
+				}
+			}
+		],
+		"sign.signer": "%s"
+	},
+	"sort": [{"sign.signer": "desc"}, {"canceled_time": "desc"}],
+	"use_index": ["contract", "signer-canceled-time"]
+}`
+
+// CreateQueryCanceledContractsBySignerCCIDs _
+func CreateQueryCanceledContractsBySignerCCIDs(kid string, ccids []string) string {
+	return fmt.Sprintf(QueryCanceledContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid)
+}
+
+// QueryDisapprovedContractsBySignerCCIDs _
+const QueryDisapprovedContractsBySignerCCIDs = `{
+	"selector": {%s
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"disapprover": {
+					"$exists": true
+				}
+			}
+		],
+		"sign.signer": "%s"
+	},
+	"sort": [{"sign.signer": "desc"}, {"canceled_time": "desc"}],
+	"use_index": ["contract", "signer-disapproved-time"]
+}`
+
+// CreateQueryDisapprovedContractsBySignerCCIDs _
+func CreateQueryDisapprovedContractsBySignerCCIDs(kid string, ccids []string) string {
+	return fmt.Sprintf(QueryDisapprovedContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid)
+}
+
+// QueryExpiredContractsBySignerCCIDs - neither executed nor canceled until the expiry time
+const QueryExpiredContractsBySignerCCIDs = `{
+	"selector": {%s
+		"$and": [
+			{
+				"@contract": {
+					"$exists": true
+				}
+			},
+			{
+				"executed_time": {
+					"$exists": false
+				}
+			},
+			{
+				"canceled_time": {
+					"$exists": false
+				}
+			}
+		],
+		"sign.signer": "%s",
+		"expiry_time": {
+			"$lte": "%s"
+		}
+	},
+	"sort": [{"sign.signer": "desc"}, {"expiry_time": "desc"}],
+	"use_index": ["contract", "signer-expired-expiry-time"]
+}`
+
+// CreateQueryExpiredContractsBySignerCCIDs _
+func CreateQueryExpiredContractsBySignerCCIDs(kid string, ccids []string, ts *txtime.Time) string {
+	return fmt.Sprintf(QueryExpiredContractsBySignerCCIDs, CreateCCIDsCondition(ccids), kid, ts.String())
+}
+
+// CreateQueryContractsBySignerOption creates the query of the option
+// option - 1 of [finished, unfinished, approved, unsigned, executed, canceled, disapproved, expired, all]
+func CreateQueryContractsBySignerOption(kid, ccid, opt string, ts *txtime.Time) string {
+	switch opt {
+	case "finished": // finished|finished_time|desc
+		return CreateQueryFinishedContractsBySigner(kid, ccid, ts)
+	case "executed": // executed|executed_time|desc
+		return CreateQueryExecutedContractsBySigner(kid, ccid)
+	case "canceled": // canceled|canceled_time|desc
+		return CreateQueryCanceledContractsBySigner(kid, ccid)
+	case "disapproved": // disapproved|canceled_time|desc
+		return CreateQueryDisapprovedContractsBySigner(kid, ccid)
+	case "expired": // expired|expiry_time|desc
+		return CreateQueryExpiredContractsBySigner(kid, ccid, ts)
+	case "unfinished": // unfinished|expiry_time|asc
+		return CreateQueryUnfinishedContractsBySigner(kid, ccid, ts)
+	case "approved": // unfinished|approved|expiry_time|asc
+		return CreateQueryApprovedContractsBySigner(kid, ccid, ts)
+	case "unsigned": // unfinished|unsigned|expiry_time|asc
+		return CreateQueryUnsignedContractsBySigner(kid, ccid, ts)
+	default: // all|created_time|desc
+		return CreateQueryContractsBySigner(kid, ccid)
+	}
+}
+
+// CreateQueryContractsBySignerCCIDsOption creates the query of the option across chaincodes
+func CreateQueryContractsBySignerCCIDsOption(kid string, ccids []string, opt string, ts *txtime.Time) string {
+	switch opt {
+	case "finished":
+		return CreateQueryFinishedContractsBySignerCCIDs(kid, ccids, ts)
+	case "executed":
+		return CreateQueryExecutedContractsBySignerCCIDs(kid, ccids)
+	case "canceled":
+		return CreateQueryCanceledContractsBySignerCCIDs(kid, ccids)
+	case "disapproved":
+		return CreateQueryDisapprovedContractsBySignerCCIDs(kid, ccids)
+	case "expired":
+		return CreateQueryExpiredContractsBySignerCCIDs(kid, ccids, ts)
+	case "unfinished":
+		return CreateQueryUnfinishedContractsBySignerCCIDs(kid, ccids, ts)
+	case "approved":
+		return CreateQueryApprovedContractsBySignerCCIDs(kid, ccids, ts)
+	case "unsigned":
+		return CreateQueryUnsignedContractsBySignerCCIDs(kid, ccids, ts)
+	default:
+		return CreateQueryContractsBySignerCCIDs(kid, ccids)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+	"github.com/pkg/errors"
+)
+
+// RegistrationLimits represents limits of the chaincode creating contracts
+type RegistrationLimits struct {
+	MaxSigners        int      `json:"max_signers,omitempty"`        // 0 means 'max_signers' of the config
+	MaxDocumentSize   int      `json:"max_document_size,omitempty"`  // bytes, 0 means unlimited
+	CallbackFunctions []string `json:"callback_functions,omitempty"` // allowed callback functions, empty means any
+}
+
+// Validate _
+func (l *RegistrationLimits) Validate(cfg *Config) error {
+	if l.MaxSigners != 0 && (l.MaxSigners < 2 || l.MaxSigners > cfg.MaxSigners) {
+		return errors.Errorf("invalid max_signers, expecting 2 ~ %d", cfg.MaxSigners)
+	}
+	if l.MaxDocumentSize < 0 {
+		return errors.New("invalid max_document_size, expecting 0+")
+	}
+	return nil
+}
+
+// Registration is the registration of the chaincode (CCID) allowed to create contracts
+type Registration struct {
+	DOCTYPEID string `json:"@registration"` // CCID
+	RegistrationLimits
+	RegisteredTime  *txtime.Time `json:"registered_time,omitempty"`
+	UpdatedTime     *txtime.Time `json:"updated_time,omitempty"`
+	UpdatedBy       string       `json:"updated_by,omitempty"`
+	SuspendedTime   *txtime.Time `json:"suspended_time,omitempty"`
+	SuspendedReason string       `json:"suspended_reason,omitempty"`
+}
+
+// GetMaxSigners returns the max number of signers of the chaincode
+func (r *Registration) GetMaxSigners(cfg *Config) int {
+	if r.MaxSigners > 0 && r.MaxSigners < cfg.MaxSigners {
+		return r.MaxSigners
+	}
+	return cfg.MaxSigners
+}
+
+// AssertActive _
+func (r *Registration) AssertActive() error {
+	if r.SuspendedTime != nil {
+		return NewContractError(client.ErrorCodeSuspendedChaincode, "suspended chaincode: ["+r.DOCTYPEID+"]")
+	}
+	return nil
+}
+
+// AssertDocument _
+func (r *Registration) AssertDocument(document string) error {
+	if r.MaxDocumentSize > 0 && len(document) > r.MaxDocumentSize {
+		return NewContractError(client.ErrorCodeInvalidDocument, fmt.Sprintf("too large document, max %d bytes", r.MaxDocumentSize))
+	}
+	return nil
+}
+
+// AssertCallbackTarget asserts that callback functions of the target are allowed
+func (r *Registration) AssertCallbackTarget(target *CallbackTarget) error {
+	if len(r.CallbackFunctions) == 0 {
+		return nil
+	}
+	for _, fn := range []string{target.Execute, target.Cancel, target.Approve} {
+		if fn != "" && !contains(r.CallbackFunctions, fn) {
+			return NewContractError(client.ErrorCodeInvalidAccess, "not allowed callback function: ["+fn+"]")
+		}
+	}
+	return nil
+}
+
+// MarshalPayload _
+func (r *Registration) MarshalPayload() ([]byte, error) {
+	return json.Marshal(r)
+}
@@ -0,0 +1,98 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+	"github.com/pkg/errors"
+)
+
+// RegistrationKeyPrefix _
+const RegistrationKeyPrefix = "CRG_"
+
+// RegistrationStub _
+type RegistrationStub struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewRegistrationStub _
+func NewRegistrationStub(stub shim.ChaincodeStubInterface) *RegistrationStub {
+	return &RegistrationStub{stub}
+}
+
+// CreateKey _
+func (rb *RegistrationStub) CreateKey(ccid string) string {
+	return RegistrationKeyPrefix + ccid
+}
+
+// GetRegistration returns the registration of the chaincode, or nil if it's not registered
+func (rb *RegistrationStub) GetRegistration(ccid string) (*Registration, error) {
+	data, err := rb.stub.GetState(rb.CreateKey(ccid))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the registration state")
+	}
+	if nil == data {
+		return nil, nil
+	}
+	reg := &Registration{}
+	if err = json.Unmarshal(data, reg); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the registration")
+	}
+	return reg, nil
+}
+
+// GetCreatorRegistration returns the registration of the chaincode creating contracts.
+// If the registration isn't required by the config, it can be nil.
+func (rb *RegistrationStub) GetCreatorRegistration(ccid string, cfg *Config) (*Registration, error) {
+	reg, err := rb.GetRegistration(ccid)
+	if err != nil {
+		return nil, err
+	}
+	if nil == reg {
+		if cfg.RequireRegistration {
+			return nil, NewContractError(client.ErrorCodeUnregisteredChaincode, "unregistered chaincode: ["+ccid+"]")
+		}
+		return nil, nil
+	}
+	if err = reg.AssertActive(); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// PutRegistration _
+func (rb *RegistrationStub) PutRegistration(reg *Registration, admin string) error {
+	ts, err := txtime.GetTime(rb.stub)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the timestamp")
+	}
+	if nil == reg.RegisteredTime {
+		reg.RegisteredTime = ts
+	}
+	reg.UpdatedTime = ts
+	reg.UpdatedBy = admin
+	data, err := json.Marshal(reg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the registration")
+	}
+	if err = rb.stub.PutState(rb.CreateKey(reg.DOCTYPEID), data); err != nil {
+		return errors.Wrap(err, "failed to put the registration state")
+	}
+	return nil
+}
+
+// GetRegistrations _
+func (rb *RegistrationStub) GetRegistrations(bookmark string, pageSize int32) (*QueryResult, error) {
+	iter, meta, err := rb.stub.GetStateByRangeWithPagination(RegistrationKeyPrefix, RegistrationKeyPrefix+string(utf8.MaxRune), pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	return NewQueryResult(meta, iter)
+}
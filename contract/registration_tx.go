@@ -0,0 +1,215 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// params[0] : ccid
+func registrationGet(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	reg, err := NewRegistrationStub(stub).GetRegistration(params[0])
+	if err != nil {
+		return responseError(err, "failed to get the registration")
+	}
+	if nil == reg {
+		return responseCode(client.ErrorCodeUnregisteredChaincode, "unregistered chaincode: ["+params[0]+"]")
+	}
+
+	return response(reg)
+}
+
+// params[0] : bookmark (optional)
+func registrationList(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	bookmark := ""
+	if len(params) > 0 {
+		bookmark = params[0]
+	}
+
+	cfg, err := NewConfigStub(stub).GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+
+	res, err := NewRegistrationStub(stub).GetRegistrations(bookmark, cfg.FetchSize)
+	if err != nil {
+		return responseError(err, "failed to get registrations list")
+	}
+
+	return response(res)
+}
+
+// params[0] : ccid
+// params[1] : limits JSON object (optional)
+func registrationRegister(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) < 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1+")
+	}
+
+	// authentication
+	admin, err := GetAdmin(stub)
+	if err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	cfg, err := NewConfigStub(stub).GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+	ccid := params[0]
+	if "" == ccid || cfg.IsBlockedCCID(ccid) {
+		return responseCode(client.ErrorCodeInvalidParameters, "invalid ccid")
+	}
+
+	rb := NewRegistrationStub(stub)
+	reg, err := rb.GetRegistration(ccid)
+	if err != nil {
+		return responseError(err, "failed to register the chaincode")
+	}
+	if reg != nil {
+		return responseCode(client.ErrorCodeAlreadyRegistered, "already registered chaincode")
+	}
+
+	reg = &Registration{DOCTYPEID: ccid}
+	if len(params) > 1 {
+		if err = json.Unmarshal([]byte(params[1]), &reg.RegistrationLimits); err != nil {
+			return responseCode(client.ErrorCodeInvalidParameters, "failed to unmarshal the limits")
+		}
+	}
+	if err = reg.Validate(cfg); err != nil {
+		return responseCode(client.ErrorCodeInvalidParameters, err.Error())
+	}
+	if err = rb.PutRegistration(reg, admin); err != nil {
+		return responseError(err, "failed to register the chaincode")
+	}
+
+	return response(reg)
+}
+
+// params[0] : ccid
+func registrationResume(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 1 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 1")
+	}
+
+	// authentication
+	admin, err := GetAdmin(stub)
+	if err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	rb := NewRegistrationStub(stub)
+	reg, err := rb.GetRegistration(params[0])
+	if err != nil {
+		return responseError(err, "failed to resume the chaincode")
+	}
+	if nil == reg {
+		return responseCode(client.ErrorCodeUnregisteredChaincode, "unregistered chaincode: ["+params[0]+"]")
+	}
+	if nil == reg.SuspendedTime {
+		return responseCode(client.ErrorCodeNotSuspended, "not suspended chaincode")
+	}
+
+	reg.SuspendedTime = nil
+	reg.SuspendedReason = ""
+	if err = rb.PutRegistration(reg, admin); err != nil {
+		return responseError(err, "failed to resume the chaincode")
+	}
+
+	return response(reg)
+}
+
+// params[0] : ccid
+// params[1] : reason
+func registrationSuspend(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 2 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 2")
+	}
+
+	// authentication
+	admin, err := GetAdmin(stub)
+	if err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	rb := NewRegistrationStub(stub)
+	reg, err := rb.GetRegistration(params[0])
+	if err != nil {
+		return responseError(err, "failed to suspend the chaincode")
+	}
+	if nil == reg {
+		return responseCode(client.ErrorCodeUnregisteredChaincode, "unregistered chaincode: ["+params[0]+"]")
+	}
+	if reg.SuspendedTime != nil {
+		return responseCode(client.ErrorCodeAlreadySuspended, "already suspended chaincode")
+	}
+
+	reason := params[1]
+	if "" == reason {
+		return responseCode(client.ErrorCodeInvalidParameters, "reason is required")
+	}
+
+	ts, err := txtime.GetTime(stub)
+	if err != nil {
+		return responseError(err, "failed to suspend the chaincode")
+	}
+
+	reg.SuspendedTime = ts
+	reg.SuspendedReason = reason
+	if err = rb.PutRegistration(reg, admin); err != nil {
+		return responseError(err, "failed to suspend the chaincode")
+	}
+
+	return response(reg)
+}
+
+// params[0] : ccid
+// params[1] : limits JSON object (replaces all limits)
+func registrationUpdate(stub shim.ChaincodeStubInterface, params []string) peer.Response {
+	if len(params) != 2 {
+		return responseCode(client.ErrorCodeInvalidParameters, "incorrect number of parameters. expecting 2")
+	}
+
+	// authentication
+	admin, err := GetAdmin(stub)
+	if err != nil {
+		return responseError(err, "failed to authenticate the administrator")
+	}
+
+	cfg, err := NewConfigStub(stub).GetConfig()
+	if err != nil {
+		return responseError(err, "failed to get the config")
+	}
+
+	rb := NewRegistrationStub(stub)
+	reg, err := rb.GetRegistration(params[0])
+	if err != nil {
+		return responseError(err, "failed to update the registration")
+	}
+	if nil == reg {
+		return responseCode(client.ErrorCodeUnregisteredChaincode, "unregistered chaincode: ["+params[0]+"]")
+	}
+
+	limits := RegistrationLimits{}
+	if err = json.Unmarshal([]byte(params[1]), &limits); err != nil {
+		return responseCode(client.ErrorCodeInvalidParameters, "failed to unmarshal the limits")
+	}
+	if err = limits.Validate(cfg); err != nil {
+		return responseCode(client.ErrorCodeInvalidParameters, err.Error())
+	}
+	reg.RegistrationLimits = limits
+	if err = rb.PutRegistration(reg, admin); err != nil {
+		return responseError(err, "failed to update the registration")
+	}
+
+	return response(reg)
+}
@@ -0,0 +1,130 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxSearchFields is the max number of document fields of the search selector
+const MaxSearchFields = 8
+
+var searchFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// allowed operators of the search selector, and whether the value is an array
+var searchOperators = map[string]bool{
+	"$eq":     false,
+	"$ne":     false,
+	"$gt":     false,
+	"$gte":    false,
+	"$lt":     false,
+	"$lte":    false,
+	"$exists": false,
+	"$in":     true,
+	"$nin":    true,
+}
+
+// ParseDocumentFields returns the document as structured JSON, or nil if it's not a JSON object
+func ParseDocumentFields(document string) json.RawMessage {
+	if !strings.HasPrefix(strings.TrimSpace(document), "{") || !json.Valid([]byte(document)) {
+		return nil
+	}
+	return json.RawMessage(document)
+}
+
+// CreateDocumentSelector validates the search selector over document fields,
+// and returns selector fields of contract headers. (document_fields.*)
+// ex. {"type": "transfer", "amount": {"$gte": 100}}
+func CreateDocumentSelector(param string) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewBufferString(param))
+	dec.UseNumber() // keep numbers as they are
+	fields := map[string]interface{}{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, errors.New("invalid selector, expecting JSON object")
+	}
+	if len(fields) == 0 || len(fields) > MaxSearchFields {
+		return nil, errors.Errorf("invalid selector, expecting 1 ~ %d fields", MaxSearchFields)
+	}
+	selector := map[string]interface{}{}
+	for name, cond := range fields {
+		if !searchFieldPattern.MatchString(name) {
+			return nil, errors.Errorf("invalid selector field: [%s]", name)
+		}
+		if err := validateSearchCondition(name, cond); err != nil {
+			return nil, err
+		}
+		selector["document_fields."+name] = cond
+	}
+	return selector, nil
+}
+
+func validateSearchCondition(name string, cond interface{}) error {
+	ops, ok := cond.(map[string]interface{})
+	if !ok {
+		if !isSearchScalar(cond) {
+			return errors.Errorf("invalid value of the field: [%s]", name)
+		}
+		return nil
+	}
+	if len(ops) == 0 {
+		return errors.Errorf("empty condition of the field: [%s]", name)
+	}
+	for op, v := range ops {
+		array, ok := searchOperators[op]
+		if !ok {
+			return errors.Errorf("not allowed operator: [%s]", op)
+		}
+		if array {
+			values, ok := v.([]interface{})
+			if !ok {
+				return errors.Errorf("invalid value of the operator: [%s]", op)
+			}
+			for _, e := range values {
+				if !isSearchScalar(e) {
+					return errors.Errorf("invalid value of the operator: [%s]", op)
+				}
+			}
+		} else if _, isBool := v.(bool); "$exists" == op && !isBool {
+			return errors.Errorf("invalid value of the operator: [%s]", op)
+		} else if !isSearchScalar(v) {
+			return errors.Errorf("invalid value of the operator: [%s]", op)
+		}
+	}
+	return nil
+}
+
+func isSearchScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, json.Number, bool:
+		return true
+	}
+	return false
+}
+
+// CreateQuerySearchContracts creates the query of contract headers.
+// It's scoped to the signer (kid) or the chaincode (ccid), one of them must be set.
+// CouchDB can't index elements of arrays, so the signer scope ($elemMatch of signers) is filtered
+// while scanning headers by created_time, and the cost grows with the number of all contracts.
+func CreateQuerySearchContracts(kid, ccid string, selector map[string]interface{}) (string, error) {
+	selector["@contract_header"] = map[string]interface{}{"$exists": true}
+	query := map[string]interface{}{"selector": selector}
+	if kid != "" {
+		selector["signers"] = map[string]interface{}{"$elemMatch": map[string]interface{}{"$eq": kid}}
+		query["sort"] = []interface{}{map[string]string{"created_time": "desc"}}
+		query["use_index"] = []string{"contract", "header-created-time"}
+	} else {
+		selector["ccid"] = ccid
+		query["sort"] = []interface{}{map[string]string{"ccid": "desc"}, map[string]string{"created_time": "desc"}}
+		query["use_index"] = []string{"contract", "header-ccid-created-time"}
+	}
+	data, err := json.Marshal(query)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the query")
+	}
+	return string(data), nil
+}
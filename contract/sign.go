@@ -0,0 +1,34 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// Sign represents signer's action. (approve, disapprove or cancel request)
+type Sign struct {
+	Signer          string       `json:"signer"`
+	ApprovedTime    *txtime.Time `json:"approved_time,omitempty"`
+	DisapprovedTime *txtime.Time `json:"disapproved_time,omitempty"`
+	// quorum cancel policy only
+	CancelRequestedTime *txtime.Time `json:"cancel_requested_time,omitempty"`
+	Comment             string       `json:"comment,omitempty"` // of the approval or the disapproval
+	// signer's signature over the contract hash (optional, approval only)
+	Signature *client.Signature `json:"signature,omitempty"`
+}
+
+// SignRecord is the per-signer state document.
+// It has index fields of the contract for queries, but not the document.
+type SignRecord struct {
+	DOCTYPEID    string       `json:"@contract"`
+	CCID         string       `json:"ccid"`
+	CreatedTime  *txtime.Time `json:"created_time,omitempty"`
+	ExpiryTime   *txtime.Time `json:"expiry_time,omitempty"`
+	ExecutedTime *txtime.Time `json:"executed_time,omitempty"`
+	CanceledTime *txtime.Time `json:"canceled_time,omitempty"`
+	FinishedTime *txtime.Time `json:"finished_time,omitempty"`
+	Disapprover  string       `json:"disapprover,omitempty"`
+	Sign         *Sign        `json:"sign"`
+}
@@ -0,0 +1,72 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package contract
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/key-inside/kiesnet-ccpkg/txtime"
+	"github.com/payprotocol/kiesnet-contract/client"
+)
+
+// PublicKey is the registered public key of the signer (KID).
+// Signatures over contract hashes are verified by it. (approve)
+type PublicKey struct {
+	DOCTYPEID      string       `json:"@public_key"` // KID
+	Algorithm      string       `json:"algorithm"`   // ecdsa or ed25519
+	Key            string       `json:"key"`         // base64 PKIX (DER)
+	RegisteredTime *txtime.Time `json:"registered_time,omitempty"`
+	UpdatedTime    *txtime.Time `json:"updated_time,omitempty"`
+}
+
+// MarshalPayload _
+func (k *PublicKey) MarshalPayload() ([]byte, error) {
+	return json.Marshal(k)
+}
+
+// NewPublicKey parses the PEM or base64 encoded PKIX public key of the signer
+func NewPublicKey(kid, key string) (*PublicKey, error) {
+	algorithm, der, err := client.ParsePublicKey(key)
+	if err != nil {
+		return nil, NewContractError(client.ErrorCodeInvalidPublicKey, err.Error())
+	}
+	return &PublicKey{
+		DOCTYPEID: kid,
+		Algorithm: algorithm,
+		Key:       base64.StdEncoding.EncodeToString(der),
+	}, nil
+}
+
+// Verify verifies the signature over the hash, and returns the signature to be stored on the sign
+func (k *PublicKey) Verify(hash, sig []byte) (*client.Signature, error) {
+	der, err := base64.StdEncoding.DecodeString(k.Key)
+	if err != nil {
+		return nil, NewContractError(client.ErrorCodeInvalidPublicKey, "invalid public key encoding")
+	}
+	if err = client.VerifySignature(der, hash, sig); err != nil {
+		return nil, NewContractError(client.ErrorCodeInvalidSignature, err.Error())
+	}
+	return &client.Signature{
+		Algorithm: k.Algorithm,
+		PublicKey: k.Key,
+		Hash:      hex.EncodeToString(hash),
+		Value:     base64.StdEncoding.EncodeToString(sig),
+	}, nil
+}
+
+// NewContractDigest returns the digest of the contract signed by signers. (see client.ContractDigest)
+// Signers of the contract must be known. (see GetSignRecords)
+func NewContractDigest(contract *Contract) *client.ContractDigest {
+	return client.NewContractDigest(contract.DOCTYPEID, contract.CCID, contract.Creator, contract.Document,
+		contract.signers, stdTime(contract.CreatedTime), stdTime(contract.ExpiryTime))
+}
+
+func stdTime(t *txtime.Time) *time.Time {
+	if nil == t {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,42 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package testkit
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/key-inside/kiesnet-ccpkg/kid"
+)
+
+// KIDResponder is the fake kiesnet-id chaincode.
+// It responds the KID of the current user to 'kid' requests of kid.GetID.
+type KIDResponder struct {
+	ID        string // KID of the current user, empty means the user has no KID
+	PINFailed bool   // if true, secure requests (PIN required) fail
+}
+
+// NewKIDResponderStub returns the stub of the fake kiesnet-id, named by the kid package config
+func NewKIDResponderStub(responder *KIDResponder) *MockStub {
+	return NewMockStub(kid.KIDCfg.CC, responder)
+}
+
+// Init implements shim.Chaincode interface.
+func (r *KIDResponder) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+// Invoke implements shim.Chaincode interface.
+// params[0] : '1' if it's secure (optional)
+func (r *KIDResponder) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	fn, params := stub.GetFunctionAndParameters()
+	if fn != "kid" {
+		return shim.Error("unknown function: [" + fn + "]")
+	}
+	if r.ID == "" {
+		return shim.Error("failed to get the KID")
+	}
+	if len(params) > 0 && params[0] == "1" && r.PINFailed {
+		return shim.Error("failed to authenticate the PIN")
+	}
+	return shim.Success([]byte(r.ID))
+}
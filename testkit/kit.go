@@ -0,0 +1,177 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package testkit
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/payprotocol/kiesnet-contract/client"
+	"github.com/payprotocol/kiesnet-contract/contract"
+	"github.com/pkg/errors"
+)
+
+// ContractName is the chaincode name of kiesnet-contract in the kit
+const ContractName = "kiesnet-contract"
+
+// Kit is the in-memory network of the fake kiesnet-id, kiesnet-contract and the chaincode under test.
+type Kit struct {
+	KID       *KIDResponder
+	IDStub    *MockStub
+	Contract  *MockStub
+	Chaincode *MockStub
+	seq       int
+}
+
+// NewKit wires stubs of the network. The chaincode under test is named 'name'.
+func NewKit(name string, cc shim.Chaincode) *Kit {
+	k := &Kit{KID: &KIDResponder{}}
+	k.IDStub = NewKIDResponderStub(k.KID)
+	k.Contract = NewMockStub(ContractName, contract.New())
+	k.Chaincode = NewMockStub(name, cc)
+
+	k.Contract.MockPeer(k.IDStub.Name, k.IDStub)
+	k.Contract.MockPeer(name, k.Chaincode)
+	k.Chaincode.MockPeer(k.IDStub.Name, k.IDStub)
+	k.Chaincode.MockPeer(ContractName, k.Contract)
+	return k
+}
+
+// NextTxID returns the unique transaction ID
+func (k *Kit) NextTxID() string {
+	k.seq++
+	return "tx" + strconv.Itoa(k.seq)
+}
+
+// InitContract initializes kiesnet-contract, empty args means the default config
+func (k *Kit) InitContract(args ...string) pb.Response {
+	return k.Contract.MockInit(k.NextTxID(), toBytes("init", args))
+}
+
+// Register registers the chaincode under test as the administrator (admins of the config), empty limits means defaults.
+// Only registered chaincodes can create contracts, unless require_registration of the config is false.
+func (k *Kit) Register(admin, limits string) pb.Response {
+	params := []string{k.Chaincode.Name}
+	if limits != "" {
+		params = append(params, limits)
+	}
+	return k.Invoke(k.Contract, admin, client.FnCCIDRegister, params...)
+}
+
+// Invoke invokes the chaincode of the stub directly, as the user (kid)
+func (k *Kit) Invoke(stub *MockStub, kid, fn string, params ...string) pb.Response {
+	k.KID.ID = kid
+	return stub.MockInvoke(k.NextTxID(), toBytes(fn, params))
+}
+
+// Create creates the contract as if the chaincode under test invoked 'create' for the creator.
+// expiry is seconds or options JSON object.
+func (k *Kit) Create(creator, document, expiry string, signers ...string) (string, error) {
+	k.KID.ID = creator
+	params := append([]string{document, expiry}, signers...)
+	res := k.Contract.MockInvokeBy(k.Chaincode.Name, k.NextTxID(), toBytes(client.FnCreate, params))
+	if res.GetStatus() != shim.OK {
+		return "", client.ParseError(res.GetMessage())
+	}
+	contract, err := client.DecodeContract(res.GetPayload())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal the contract")
+	}
+	return contract.ID, nil
+}
+
+// Approve approves the contract as the signer
+func (k *Kit) Approve(signer, id, comment string) pb.Response {
+	return k.Invoke(k.Contract, signer, client.FnApprove, id, comment)
+}
+
+// Disapprove disapproves the contract as the signer, it invokes the cancel callback
+func (k *Kit) Disapprove(signer, id, comment string) pb.Response {
+	return k.Invoke(k.Contract, signer, client.FnDisapprove, id, comment)
+}
+
+// Execute executes the contract approved by all signers (or retries the failed execution) as the signer
+func (k *Kit) Execute(signer, id string) pb.Response {
+	return k.Invoke(k.Contract, signer, client.FnExecute, id)
+}
+
+// CallbackTarget returns the callback target of the contract, as the chaincode under test gets the detail.
+// If the contract doesn't have the target, it's the default target of the chaincode under test.
+func (k *Kit) CallbackTarget(id string) (*client.CallbackTarget, error) {
+	res := k.Contract.MockInvokeBy(k.Chaincode.Name, k.NextTxID(), toBytes(client.FnDetail, []string{id}))
+	if res.GetStatus() != shim.OK {
+		return nil, client.ParseError(res.GetMessage())
+	}
+	c, err := client.DecodeContract(res.GetPayload())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the contract")
+	}
+	if c.CallbackTarget != nil {
+		return c.CallbackTarget, nil
+	}
+	target := contract.NewCallbackTarget(c.CCID, "", "", "", "", "")
+	return &client.CallbackTarget{
+		Chaincode: target.Chaincode,
+		Channel:   target.Channel,
+		Execute:   target.Execute,
+		Cancel:    target.Cancel,
+		Approve:   target.Approve,
+	}, nil
+}
+
+// Callbacks returns invocations of the callback function of the chaincode for the contract
+func (k *Kit) Callbacks(chaincode, fn, id string) []*Invocation {
+	callbacks := []*Invocation{}
+	for _, inv := range k.Contract.Invocations {
+		if inv.Chaincode == chaincode && inv.Function() == fn && inv.Param(0) == id {
+			callbacks = append(callbacks, inv)
+		}
+	}
+	return callbacks
+}
+
+// AssertExecuted asserts that the execute callback of the callback target of the contract has succeeded
+func (k *Kit) AssertExecuted(t testing.TB, id string) {
+	t.Helper()
+	target, err := k.CallbackTarget(id)
+	if err != nil {
+		t.Fatalf("failed to get the callback target of the contract [%s]: %s", id, err)
+	}
+	k.assertCallback(t, target.Chaincode, target.Execute, id)
+}
+
+// AssertCanceled asserts that the cancel callback of the callback target of the contract has succeeded
+func (k *Kit) AssertCanceled(t testing.TB, id string) {
+	t.Helper()
+	target, err := k.CallbackTarget(id)
+	if err != nil {
+		t.Fatalf("failed to get the callback target of the contract [%s]: %s", id, err)
+	}
+	k.assertCallback(t, target.Chaincode, target.Cancel, id)
+}
+
+func (k *Kit) assertCallback(t testing.TB, chaincode, fn, id string) {
+	t.Helper()
+	callbacks := k.Callbacks(chaincode, fn, id)
+	if len(callbacks) == 0 {
+		t.Fatalf("'%s' of the contract [%s] is not invoked", fn, id)
+	}
+	last := callbacks[len(callbacks)-1]
+	if last.Response.GetStatus() != shim.OK {
+		t.Fatalf("'%s' of the contract [%s] failed: %s", fn, id, last.Response.GetMessage())
+	}
+}
+
+// toBytes returns args of the function and params
+func toBytes(fn string, params []string) [][]byte {
+	args := [][]byte{}
+	if fn != "" {
+		args = append(args, []byte(fn))
+	}
+	for _, p := range params {
+		args = append(args, []byte(p))
+	}
+	return args
+}
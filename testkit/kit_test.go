@@ -0,0 +1,95 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package testkit
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// dealChaincode is the chaincode under test, it stores the status of contracts by callbacks
+type dealChaincode struct{}
+
+func (cc *dealChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+// params[0] : contract ID
+func (cc *dealChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	fn, params := stub.GetFunctionAndParameters()
+	if len(params) < 1 {
+		return shim.Error("incorrect number of parameters. expecting 1+")
+	}
+	switch fn {
+	case "contract/execute", "deal/execute":
+		return cc.put(stub, params[0], "executed")
+	case "contract/cancel", "deal/cancel":
+		return cc.put(stub, params[0], "canceled")
+	}
+	return shim.Error("unknown function: [" + fn + "]")
+}
+
+func (cc *dealChaincode) put(stub shim.ChaincodeStubInterface, id, status string) pb.Response {
+	if err := stub.PutState("DEAL_"+id, []byte(status)); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+func newDealKit(t *testing.T) *Kit {
+	k := NewKit("deal", &dealChaincode{})
+	if res := k.InitContract(`{"admins": ["admin"]}`); res.GetStatus() != shim.OK {
+		t.Fatalf("failed to init: %s", res.GetMessage())
+	}
+	if res := k.Register("admin", ""); res.GetStatus() != shim.OK {
+		t.Fatalf("failed to register: %s", res.GetMessage())
+	}
+	return k
+}
+
+func TestKit(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiry     string
+		execute    bool // approved and executed by signers, or disapproved
+		wantStatus string
+	}{
+		{"execute", "3600", true, "executed"},
+		{"cancel", "3600", false, "canceled"},
+		{"execute by the callback target", `{"expiry": 3600, "callback_target": {"execute": "deal/execute", "cancel": "deal/cancel"}}`, true, "executed"},
+		{"cancel by the callback target", `{"expiry": 3600, "callback_target": {"execute": "deal/execute", "cancel": "deal/cancel"}}`, false, "canceled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := newDealKit(t)
+			id, err := k.Create("alice", `{"amount": 100}`, tt.expiry, "bob", "carol")
+			if err != nil {
+				t.Fatalf("failed to create: %s", err)
+			}
+			if tt.execute {
+				for _, signer := range []string{"bob", "carol"} {
+					if res := k.Approve(signer, id, ""); res.GetStatus() != shim.OK {
+						t.Fatalf("failed to approve: %s", res.GetMessage())
+					}
+				}
+				if res := k.Execute("carol", id); res.GetStatus() != shim.OK {
+					t.Fatalf("failed to execute: %s", res.GetMessage())
+				}
+				k.AssertExecuted(t, id)
+			} else {
+				if res := k.Approve("bob", id, ""); res.GetStatus() != shim.OK {
+					t.Fatalf("failed to approve: %s", res.GetMessage())
+				}
+				if res := k.Disapprove("carol", id, "no"); res.GetStatus() != shim.OK {
+					t.Fatalf("failed to disapprove: %s", res.GetMessage())
+				}
+				k.AssertCanceled(t, id)
+			}
+			if status := string(k.Chaincode.State["DEAL_"+id]); status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+		})
+	}
+}
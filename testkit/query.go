@@ -0,0 +1,327 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package testkit
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/pkg/errors"
+)
+
+// Query is the CouchDB (Mango) query.
+// 'use_index' is ignored, results are sorted by 'sort' fields and keys.
+type Query struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []interface{}          `json:"sort,omitempty"`
+	UseIndex interface{}            `json:"use_index,omitempty"`
+}
+
+// ParseQuery _
+func ParseQuery(query string) (*Query, error) {
+	q := &Query{}
+	if err := json.Unmarshal([]byte(query), q); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the query")
+	}
+	if nil == q.Selector {
+		return nil, errors.New("the query has no selector")
+	}
+	return q, nil
+}
+
+// Match returns the document of the JSON value, if it matches the selector
+func (q *Query) Match(value []byte) (map[string]interface{}, bool) {
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return nil, false // not a JSON object
+	}
+	return doc, matchSelector(doc, q.Selector)
+}
+
+// SortResults sorts key-values and their documents by 'sort' fields of the query
+func (q *Query) SortResults(kvs []*queryresult.KV, docs []map[string]interface{}) {
+	type sortField struct {
+		path string
+		desc bool
+	}
+	fields := []sortField{}
+	for _, s := range q.Sort {
+		switch s := s.(type) {
+		case string:
+			fields = append(fields, sortField{path: s})
+		case map[string]interface{}:
+			for path, dir := range s {
+				fields = append(fields, sortField{path: path, desc: dir == "desc"})
+			}
+		}
+	}
+	indexes := make([]int, len(kvs))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		da, db := docs[indexes[a]], docs[indexes[b]]
+		for _, f := range fields {
+			va, _ := lookup(da, f.path)
+			vb, _ := lookup(db, f.path)
+			if c := compare(va, vb); c != 0 {
+				return (c < 0) != f.desc
+			}
+		}
+		return kvs[indexes[a]].Key < kvs[indexes[b]].Key
+	})
+	sortedKVs := make([]*queryresult.KV, len(kvs))
+	sortedDocs := make([]map[string]interface{}, len(docs))
+	for i, j := range indexes {
+		sortedKVs[i], sortedDocs[i] = kvs[j], docs[j]
+	}
+	copy(kvs, sortedKVs)
+	copy(docs, sortedDocs)
+}
+
+// matchSelector returns true if the value matches all conditions of the selector
+func matchSelector(value interface{}, selector map[string]interface{}) bool {
+	for name, cond := range selector {
+		if !matchField(value, name, cond) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchField(value interface{}, name string, cond interface{}) bool {
+	switch name {
+	case "$and", "$or", "$nor":
+		conds, ok := cond.([]interface{})
+		if !ok {
+			return false
+		}
+		matched := 0
+		for _, c := range conds {
+			if m, ok := c.(map[string]interface{}); ok && matchSelector(value, m) {
+				matched++
+			}
+		}
+		switch name {
+		case "$and":
+			return matched == len(conds)
+		case "$or":
+			return matched > 0
+		}
+		return matched == 0
+	case "$not":
+		m, ok := cond.(map[string]interface{})
+		return ok && !matchSelector(value, m)
+	}
+	doc, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	v, exists := lookup(doc, name)
+	return matchCondition(v, exists, cond)
+}
+
+// matchCondition matches the field value with the condition (operators or the implicit $eq)
+func matchCondition(v interface{}, exists bool, cond interface{}) bool {
+	ops, ok := cond.(map[string]interface{})
+	if !ok || !isOperators(ops) {
+		return exists && compare(v, cond) == 0
+	}
+	for op, arg := range ops {
+		if op == "$exists" {
+			if b, _ := arg.(bool); b != exists {
+				return false
+			}
+			continue
+		}
+		if !exists || !matchOperator(v, op, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+func isOperators(m map[string]interface{}) bool {
+	for k := range m {
+		if strings.HasPrefix(k, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperator(v interface{}, op string, arg interface{}) bool {
+	switch op {
+	case "$eq":
+		return compare(v, arg) == 0
+	case "$ne":
+		return compare(v, arg) != 0
+	case "$gt":
+		return sameType(v, arg) && compare(v, arg) > 0
+	case "$gte":
+		return sameType(v, arg) && compare(v, arg) >= 0
+	case "$lt":
+		return sameType(v, arg) && compare(v, arg) < 0
+	case "$lte":
+		return sameType(v, arg) && compare(v, arg) <= 0
+	case "$in", "$nin":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false
+		}
+		in := false
+		for _, e := range list {
+			if compare(v, e) == 0 {
+				in = true
+				break
+			}
+		}
+		return in == (op == "$in")
+	case "$all":
+		list, ok := arg.([]interface{})
+		array, isArray := v.([]interface{})
+		if !ok || !isArray {
+			return false
+		}
+		for _, e := range list {
+			found := false
+			for _, a := range array {
+				if compare(a, e) == 0 {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case "$elemMatch":
+		array, isArray := v.([]interface{})
+		m, ok := arg.(map[string]interface{})
+		if !isArray || !ok {
+			return false
+		}
+		for _, a := range array {
+			if hasFieldNames(m) {
+				if matchSelector(a, m) {
+					return true
+				}
+			} else if matchCondition(a, true, m) {
+				return true
+			}
+		}
+		return false
+	case "$size":
+		array, isArray := v.([]interface{})
+		n, ok := arg.(float64)
+		return isArray && ok && float64(len(array)) == n
+	case "$type":
+		return typeName(v) == arg
+	case "$regex":
+		s, ok := v.(string)
+		pattern, isString := arg.(string)
+		if !ok || !isString {
+			return false
+		}
+		matched, err := regexp.MatchString(pattern, s)
+		return err == nil && matched
+	case "$not":
+		return !matchCondition(v, true, arg)
+	}
+	return false // unsupported operator
+}
+
+// hasFieldNames returns true if the map has any key which is not an operator
+func hasFieldNames(m map[string]interface{}) bool {
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the value of the dotted path (ex. 'sign.signer')
+func lookup(doc map[string]interface{}, path string) (interface{}, bool) {
+	var v interface{} = doc
+	for _, name := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// typeName returns the CouchDB type name of the JSON value
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// typeRank is the collation order of CouchDB types
+var typeRank = map[string]int{"null": 0, "boolean": 1, "number": 2, "string": 3, "array": 4, "object": 5}
+
+// sameType returns true if the values are same type (range operators don't match other types)
+func sameType(a, b interface{}) bool {
+	return typeName(a) == typeName(b)
+}
+
+// compare compares JSON values by the collation order of CouchDB (strings are compared by bytes)
+func compare(a, b interface{}) int {
+	ta, tb := typeName(a), typeName(b)
+	if ta != tb {
+		return typeRank[ta] - typeRank[tb]
+	}
+	switch a := a.(type) {
+	case bool:
+		if a == b.(bool) {
+			return 0
+		} else if a {
+			return 1
+		}
+		return -1
+	case float64:
+		if bv := b.(float64); a < bv {
+			return -1
+		} else if a > bv {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case []interface{}:
+		bv := b.([]interface{})
+		for i := 0; i < len(a) && i < len(bv); i++ {
+			if c := compare(a[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return len(a) - len(bv)
+	case map[string]interface{}:
+		if reflect.DeepEqual(a, b) {
+			return 0
+		}
+		return 1
+	}
+	return 0 // null
+}
@@ -0,0 +1,78 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package testkit
+
+import (
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{"selector", `{"selector": {"a": 1}}`, false},
+		{"sort and use_index", `{"selector": {"a": 1}, "sort": [{"a": "desc"}], "use_index": ["ddoc", "name"]}`, false},
+		{"no selector", `{"sort": [{"a": "desc"}]}`, true},
+		{"invalid JSON", `{"selector": `, true},
+		{"not an object", `[]`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	doc := `{"@contract": "c1", "ccid": "cc", "amount": 100, "ok": true, "sign": {"signer": "kid1"}, "signers": ["kid1", "kid2"]}`
+	tests := []struct {
+		name     string
+		selector string
+		value    string
+		want     bool
+	}{
+		{"implicit $eq", `{"ccid": "cc"}`, doc, true},
+		{"implicit $eq mismatch", `{"ccid": "other"}`, doc, false},
+		{"nested field", `{"sign.signer": "kid1"}`, doc, true},
+		{"missing field", `{"sign.comment": "x"}`, doc, false},
+		{"$exists true", `{"@contract": {"$exists": true}}`, doc, true},
+		{"$exists false", `{"@contract_header": {"$exists": false}}`, doc, true},
+		{"$gt number", `{"amount": {"$gt": 99}}`, doc, true},
+		{"$gte number", `{"amount": {"$gte": 100}}`, doc, true},
+		{"$lt number", `{"amount": {"$lt": 100}}`, doc, false},
+		{"range of other types", `{"amount": {"$gt": "1"}}`, doc, false},
+		{"$gte string", `{"@contract": {"$gte": "c0"}}`, doc, true},
+		{"$ne", `{"ccid": {"$ne": "cc"}}`, doc, false},
+		{"$in", `{"ccid": {"$in": ["a", "cc"]}}`, doc, true},
+		{"$nin", `{"ccid": {"$nin": ["a", "cc"]}}`, doc, false},
+		{"$elemMatch $eq", `{"signers": {"$elemMatch": {"$eq": "kid2"}}}`, doc, true},
+		{"$elemMatch mismatch", `{"signers": {"$elemMatch": {"$eq": "kid3"}}}`, doc, false},
+		{"$all", `{"signers": {"$all": ["kid2", "kid1"]}}`, doc, true},
+		{"$size", `{"signers": {"$size": 2}}`, doc, true},
+		{"$type", `{"ok": {"$type": "boolean"}}`, doc, true},
+		{"$regex", `{"sign.signer": {"$regex": "^kid"}}`, doc, true},
+		{"$and", `{"$and": [{"ccid": "cc"}, {"amount": 100}]}`, doc, true},
+		{"$or", `{"$or": [{"ccid": "x"}, {"amount": 100}]}`, doc, true},
+		{"$nor", `{"$nor": [{"ccid": "x"}, {"amount": 100}]}`, doc, false},
+		{"$not", `{"$not": {"ccid": "cc"}}`, doc, false},
+		{"unsupported operator", `{"ccid": {"$mod": [2, 0]}}`, doc, false},
+		{"not a JSON object", `{"ccid": "cc"}`, `"cc"`, false},
+		{"not JSON", `{"ccid": "cc"}`, `cc`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(`{"selector": ` + tt.selector + `}`)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+			if _, got := q.Match([]byte(tt.value)); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
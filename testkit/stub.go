@@ -0,0 +1,487 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+// Package testkit is the in-memory test kit for chaincodes integrating with kiesnet-contract.
+// It provides the mock stub supporting rich queries and paginations, the fake kiesnet-id
+// and helpers driving contracts.
+package testkit
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// Invocation is the record of the chaincode invocation by InvokeChaincode
+type Invocation struct {
+	TxID      string
+	Chaincode string
+	Args      [][]byte
+	Response  pb.Response
+}
+
+// Function returns the function name of the invocation
+func (i *Invocation) Function() string {
+	if len(i.Args) == 0 {
+		return ""
+	}
+	return string(i.Args[0])
+}
+
+// Param returns the i-th parameter of the invocation, or empty string
+func (i *Invocation) Param(n int) string {
+	if n+1 >= len(i.Args) {
+		return ""
+	}
+	return string(i.Args[n+1])
+}
+
+// MockStub extends shim.MockStub.
+// - rich queries (CouchDB selectors) and paginations
+// - histories of keys
+// - invocations between MockStubs, sharing the transaction and the signed proposal
+// - writes and the event are buffered by the transaction, and committed only if the response is a success
+// - rules of the peer : reads don't see writes of the transaction, the last event of the chaincode is kept,
+// paginated queries and writes can't be mixed in a transaction, and chaincodes invoked across channels can't write
+type MockStub struct {
+	*shim.MockStub
+	cc          shim.Chaincode
+	args        [][]byte
+	proposal    *pb.SignedProposal
+	tx          *txContext
+	peers       map[string]*MockStub
+	histories   map[string][]*queryresult.KeyModification
+	Events      []*pb.ChaincodeEvent // events set by transactions
+	Invocations []*Invocation        // invocations of other chaincodes
+	Now         func() time.Time     // clock of transactions, txtime allows ±5 minutes only
+}
+
+// NewMockStub _
+func NewMockStub(name string, cc shim.Chaincode) *MockStub {
+	return &MockStub{
+		MockStub:  shim.NewMockStub(name, cc),
+		cc:        cc,
+		peers:     map[string]*MockStub{},
+		histories: map[string][]*queryresult.KeyModification{},
+		Now:       time.Now,
+	}
+}
+
+// MockPeer registers the stub of the chaincode invoked by the name
+func (s *MockStub) MockPeer(name string, peer *MockStub) {
+	s.peers[name] = peer
+}
+
+// MockInit calls Init of the chaincode
+func (s *MockStub) MockInit(txID string, args [][]byte) pb.Response {
+	return s.transact(txID, s.Name, args, func() pb.Response {
+		return s.cc.Init(s)
+	})
+}
+
+// MockInvoke invokes the chaincode directly, the signed proposal is for the chaincode
+func (s *MockStub) MockInvoke(txID string, args [][]byte) pb.Response {
+	return s.MockInvokeBy(s.Name, txID, args)
+}
+
+// MockInvokeBy invokes the chaincode as if it's invoked by the chaincode (ccid) of the signed proposal
+func (s *MockStub) MockInvokeBy(ccid, txID string, args [][]byte) pb.Response {
+	return s.transact(txID, ccid, args, func() pb.Response {
+		return s.cc.Invoke(s)
+	})
+}
+
+// txContext is the simulation of the transaction, shared by stubs invoked in the same channel
+type txContext struct {
+	written   bool                                                  // writes are performed
+	paginated bool                                                  // paginated queries are performed
+	readOnly  bool                                                  // invoked across channels
+	writes    map[*MockStub]map[string]*queryresult.KeyModification // the last write of each key
+	events    map[*MockStub]*pb.ChaincodeEvent                      // the last event of each chaincode
+}
+
+// transact runs the top level transaction, and commits it if the response is a success
+func (s *MockStub) transact(txID, ccid string, args [][]byte, fn func() pb.Response) pb.Response {
+	proposal, err := createSignedProposal(s.ChannelID, ccid, args)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	tx := &txContext{}
+	res := s.call(txID, s.timestamp(), proposal, args, tx, fn)
+	if res.GetStatus() < shim.ERRORTHRESHOLD {
+		if err = tx.commit(); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	return res
+}
+
+// commit applies buffered writes and events to stubs, in key order for deterministic histories
+func (tx *txContext) commit() error {
+	for stub, writes := range tx.writes {
+		keys := make([]string, 0, len(writes))
+		for key := range writes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := stub.apply(key, writes[key]); err != nil {
+				return err
+			}
+		}
+	}
+	for stub, event := range tx.events {
+		stub.Events = append(stub.Events, event)
+	}
+	return nil
+}
+
+// call runs the chaincode in the transaction, previous context is restored for re-entrant invocations
+func (s *MockStub) call(txID string, ts *timestamp.Timestamp, proposal *pb.SignedProposal, args [][]byte, tx *txContext, fn func() pb.Response) pb.Response {
+	prevTxID, prevTs, prevArgs, prevProposal, prevTx := s.TxID, s.TxTimestamp, s.args, s.proposal, s.tx
+	defer func() {
+		s.TxID, s.TxTimestamp, s.args, s.proposal, s.tx = prevTxID, prevTs, prevArgs, prevProposal, prevTx
+	}()
+	s.MockTransactionStart(txID)
+	s.TxTimestamp, s.args, s.proposal, s.tx = ts, args, proposal, tx
+	return fn()
+}
+
+func (s *MockStub) timestamp() *timestamp.Timestamp {
+	t := s.Now()
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+// InvokeChaincode invokes the registered peer in the same transaction.
+// The peer invoked across channels (the channel isn't empty nor the channel of the stub) is read only.
+func (s *MockStub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	var res pb.Response
+	if peer := s.peers[chaincodeName]; nil == peer {
+		res = shim.Error("unknown chaincode: [" + chaincodeName + "]")
+	} else {
+		tx := s.tx
+		if channel != "" && channel != s.ChannelID {
+			tx = &txContext{readOnly: true}
+		}
+		res = peer.call(s.TxID, s.TxTimestamp, s.proposal, args, tx, func() pb.Response {
+			return peer.cc.Invoke(peer)
+		})
+	}
+	s.Invocations = append(s.Invocations, &Invocation{TxID: s.TxID, Chaincode: chaincodeName, Args: args, Response: res})
+	return res
+}
+
+// GetArgs _
+func (s *MockStub) GetArgs() [][]byte {
+	return s.args
+}
+
+// GetStringArgs _
+func (s *MockStub) GetStringArgs() []string {
+	strargs := make([]string, 0, len(s.args))
+	for _, barg := range s.args {
+		strargs = append(strargs, string(barg))
+	}
+	return strargs
+}
+
+// GetFunctionAndParameters _
+func (s *MockStub) GetFunctionAndParameters() (string, []string) {
+	allargs := s.GetStringArgs()
+	if len(allargs) == 0 {
+		return "", []string{}
+	}
+	return allargs[0], allargs[1:]
+}
+
+// GetSignedProposal _
+func (s *MockStub) GetSignedProposal() (*pb.SignedProposal, error) {
+	return s.proposal, nil
+}
+
+// SetEvent replaces the event of the chaincode in the transaction, only the last one is kept
+func (s *MockStub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be nil string")
+	}
+	event := &pb.ChaincodeEvent{ChaincodeId: s.Name, TxId: s.TxID, EventName: name, Payload: payload}
+	if nil == s.tx { // out of transactions (ex. fixtures)
+		s.Events = append(s.Events, event)
+		return nil
+	}
+	if nil == s.tx.events {
+		s.tx.events = map[*MockStub]*pb.ChaincodeEvent{}
+	}
+	s.tx.events[s] = event
+	return nil
+}
+
+// PutState buffers the write until the transaction is committed
+func (s *MockStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if nil == value {
+		value = []byte{}
+	}
+	return s.write(key, value)
+}
+
+// DelState buffers the deletion until the transaction is committed
+func (s *MockStub) DelState(key string) error {
+	return s.write(key, nil)
+}
+
+func (s *MockStub) write(key string, value []byte) error {
+	if err := s.checkWrite(); err != nil {
+		return err
+	}
+	mod := &queryresult.KeyModification{TxId: s.TxID, Value: value, Timestamp: s.TxTimestamp, IsDelete: nil == value}
+	if nil == s.tx { // out of transactions (ex. fixtures), written directly
+		return s.apply(key, mod)
+	}
+	if nil == s.tx.writes {
+		s.tx.writes = map[*MockStub]map[string]*queryresult.KeyModification{}
+	}
+	if nil == s.tx.writes[s] {
+		s.tx.writes[s] = map[string]*queryresult.KeyModification{}
+	}
+	s.tx.writes[s][key] = mod
+	return nil
+}
+
+// apply writes the modification to the state and the history of the key
+func (s *MockStub) apply(key string, mod *queryresult.KeyModification) error {
+	s.histories[key] = append(s.histories[key], mod)
+	if mod.IsDelete {
+		return s.MockStub.DelState(key)
+	}
+	prevTxID := s.TxID
+	defer func() { s.TxID = prevTxID }()
+	s.TxID = mod.TxId // shim.MockStub puts the state only in transactions
+	return s.MockStub.PutState(key, mod.Value)
+}
+
+// checkWrite rejects writes of read only invocations and writes after paginated queries
+func (s *MockStub) checkWrite() error {
+	if nil == s.tx { // out of transactions (ex. fixtures)
+		return nil
+	}
+	if s.tx.readOnly {
+		return errors.Errorf("txid [%s]: writes aren't allowed in the chaincode invoked across channels", s.TxID)
+	}
+	if s.tx.paginated {
+		return errors.Errorf("txid [%s]: writes aren't allowed after paginated queries", s.TxID)
+	}
+	s.tx.written = true
+	return nil
+}
+
+// checkPagination rejects paginated queries after writes (paginated queries are only for read only transactions)
+func (s *MockStub) checkPagination() error {
+	if nil == s.tx {
+		return nil
+	}
+	if s.tx.written {
+		return errors.Errorf("txid [%s]: paginated queries aren't allowed in transactions which perform writes", s.TxID)
+	}
+	s.tx.paginated = true
+	return nil
+}
+
+// GetHistoryForKey returns modifications of the key, the oldest first
+func (s *MockStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{mods: s.histories[key]}, nil
+}
+
+// GetStateByRangeWithPagination _
+func (s *MockStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if err := s.checkPagination(); err != nil {
+		return nil, nil, err
+	}
+	if bookmark != "" {
+		startKey = bookmark
+	}
+	iter, err := s.MockStub.GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return paginateKeys(iter, pageSize)
+}
+
+// GetStateByPartialCompositeKeyWithPagination _
+func (s *MockStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if err := s.checkPagination(); err != nil {
+		return nil, nil, err
+	}
+	iter, err := s.MockStub.GetStateByPartialCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	kvs, err := drain(iter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bookmark != "" {
+		i := sort.Search(len(kvs), func(i int) bool { return kvs[i].Key >= bookmark })
+		kvs = kvs[i:]
+	}
+	return paginateKeys(&stateIterator{kvs: kvs}, pageSize)
+}
+
+// GetQueryResult runs the CouchDB query over all JSON values of the state
+func (s *MockStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	kvs, err := s.query(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stateIterator{kvs: kvs}, nil
+}
+
+// GetQueryResultWithPagination runs the CouchDB query, the bookmark is the offset of the next page
+func (s *MockStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if err := s.checkPagination(); err != nil {
+		return nil, nil, err
+	}
+	kvs, err := s.query(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	offset := 0
+	if bookmark != "" {
+		if offset, err = strconv.Atoi(bookmark); err != nil || offset < 0 {
+			return nil, nil, errors.New("invalid bookmark")
+		}
+	}
+	if offset > len(kvs) {
+		offset = len(kvs)
+	}
+	kvs = kvs[offset:]
+	next := ""
+	if pageSize > 0 && int(pageSize) < len(kvs) {
+		kvs = kvs[:pageSize]
+		next = strconv.Itoa(offset + int(pageSize))
+	}
+	meta := &pb.QueryResponseMetadata{FetchedRecordsCount: int32(len(kvs)), Bookmark: next}
+	return &stateIterator{kvs: kvs}, meta, nil
+}
+
+func (s *MockStub) query(query string) ([]*queryresult.KV, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	kvs := []*queryresult.KV{}
+	docs := []map[string]interface{}{}
+	for e := s.Keys.Front(); e != nil; e = e.Next() {
+		key := e.Value.(string)
+		value := s.State[key]
+		doc, ok := q.Match(value)
+		if !ok {
+			continue
+		}
+		kvs = append(kvs, &queryresult.KV{Namespace: s.Name, Key: key, Value: value})
+		docs = append(docs, doc)
+	}
+	q.SortResults(kvs, docs)
+	return kvs, nil
+}
+
+// createSignedProposal creates the signed proposal of the chaincode (ccid) invocation
+func createSignedProposal(channel, ccid string, args [][]byte) (*pb.SignedProposal, error) {
+	cis := &pb.ChaincodeInvocationSpec{
+		ChaincodeSpec: &pb.ChaincodeSpec{
+			Type:        pb.ChaincodeSpec_GOLANG,
+			ChaincodeId: &pb.ChaincodeID{Name: ccid},
+			Input:       &pb.ChaincodeInput{Args: args},
+		},
+	}
+	proposal, _, err := utils.CreateChaincodeProposal(common.HeaderType_ENDORSER_TRANSACTION, channel, cis, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the proposal")
+	}
+	data, err := utils.GetBytesProposal(proposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the proposal")
+	}
+	return &pb.SignedProposal{ProposalBytes: data}, nil
+}
+
+// paginateKeys returns the page of the iterator, the bookmark is the start key of the next page
+func paginateKeys(iter shim.StateQueryIteratorInterface, pageSize int32) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	kvs, err := drain(iter)
+	if err != nil {
+		return nil, nil, err
+	}
+	next := ""
+	if pageSize > 0 && int(pageSize) < len(kvs) {
+		next = kvs[pageSize].Key
+		kvs = kvs[:pageSize]
+	}
+	meta := &pb.QueryResponseMetadata{FetchedRecordsCount: int32(len(kvs)), Bookmark: next}
+	return &stateIterator{kvs: kvs}, meta, nil
+}
+
+func drain(iter shim.StateQueryIteratorInterface) ([]*queryresult.KV, error) {
+	defer iter.Close()
+	kvs := []*queryresult.KV{}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, kv)
+	}
+	return kvs, nil
+}
+
+// stateIterator implements shim.StateQueryIteratorInterface
+type stateIterator struct {
+	kvs []*queryresult.KV
+	i   int
+}
+
+func (it *stateIterator) HasNext() bool {
+	return it.i < len(it.kvs)
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no more results")
+	}
+	it.i++
+	return it.kvs[it.i-1], nil
+}
+
+func (it *stateIterator) Close() error {
+	return nil
+}
+
+// historyIterator implements shim.HistoryQueryIteratorInterface
+type historyIterator struct {
+	mods []*queryresult.KeyModification
+	i    int
+}
+
+func (it *historyIterator) HasNext() bool {
+	return it.i < len(it.mods)
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no more histories")
+	}
+	it.i++
+	return it.mods[it.i-1], nil
+}
+
+func (it *historyIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright Key Inside Co., Ltd. 2018 All Rights Reserved.
+
+package testkit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// newFixtureStub returns the stub having documents {"n": i} of keys k0..k(count-1)
+func newFixtureStub(t *testing.T, count int) *MockStub {
+	s := NewMockStub("fixture", nil)
+	s.MockTransactionStart("fixture")
+	for i := 0; i < count; i++ {
+		if err := s.PutState(fmt.Sprintf("k%d", i), []byte(fmt.Sprintf(`{"n": %d}`, i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.PutState("raw", []byte("not JSON"))
+	s.MockTransactionEnd("fixture")
+	return s
+}
+
+func keysOf(t *testing.T, iter shim.StateQueryIteratorInterface) []string {
+	keys := []string{}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, kv.Key)
+	}
+	return keys
+}
+
+func TestGetQueryResultWithPagination(t *testing.T) {
+	s := newFixtureStub(t, 5)
+	asc := `{"selector": {"n": {"$gte": 0}}, "sort": [{"n": "asc"}]}`
+	desc := `{"selector": {"n": {"$gte": 1}}, "sort": [{"n": "desc"}]}`
+	tests := []struct {
+		name     string
+		query    string
+		pageSize int32
+		bookmark string
+		wantKeys []string
+		wantNext string
+		wantErr  bool
+	}{
+		{"first page", asc, 2, "", []string{"k0", "k1"}, "2", false},
+		{"middle page", asc, 2, "2", []string{"k2", "k3"}, "4", false},
+		{"last page", asc, 2, "4", []string{"k4"}, "", false},
+		{"exact last page", asc, 5, "", []string{"k0", "k1", "k2", "k3", "k4"}, "", false},
+		{"beyond the end", asc, 2, "9", []string{}, "", false},
+		{"no page size", asc, 0, "", []string{"k0", "k1", "k2", "k3", "k4"}, "", false},
+		{"sorted desc", desc, 3, "", []string{"k4", "k3", "k2"}, "3", false},
+		{"no match", `{"selector": {"n": {"$gt": 9}}}`, 2, "", []string{}, "", false},
+		{"invalid bookmark", asc, 2, "x", nil, "", true},
+		{"negative bookmark", asc, 2, "-1", nil, "", true},
+		{"invalid query", `{}`, 2, "", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter, meta, err := s.GetQueryResultWithPagination(tt.query, tt.pageSize, tt.bookmark)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetQueryResultWithPagination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if keys := keysOf(t, iter); !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if meta.Bookmark != tt.wantNext {
+				t.Errorf("bookmark = %q, want %q", meta.Bookmark, tt.wantNext)
+			}
+			if int(meta.FetchedRecordsCount) != len(tt.wantKeys) {
+				t.Errorf("fetched = %d, want %d", meta.FetchedRecordsCount, len(tt.wantKeys))
+			}
+		})
+	}
+}
+
+func TestPaginationAndWrites(t *testing.T) {
+	query := `{"selector": {"n": 0}}`
+	tests := []struct {
+		name    string
+		tx      *txContext
+		paging  bool // paginated query first, or write first
+		wantErr bool
+	}{
+		{"paginated query in read only transaction", &txContext{}, true, false},
+		{"write after paginated query", &txContext{}, true, true},
+		{"paginated query after write", &txContext{}, false, true},
+		{"write across channels", &txContext{readOnly: true}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFixtureStub(t, 1)
+			s.MockTransactionStart("tx")
+			s.tx = tt.tx
+			var err error
+			if tt.paging {
+				_, _, err = s.GetQueryResultWithPagination(query, 1, "")
+				if err == nil && tt.wantErr {
+					err = s.PutState("k0", []byte(`{"n": 1}`))
+				}
+			} else {
+				err = s.PutState("k0", []byte(`{"n": 1}`))
+				if err == nil {
+					_, _, err = s.GetQueryResultWithPagination(query, 1, "")
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// txChaincode runs the function of the test as the chaincode
+type txChaincode func(stub shim.ChaincodeStubInterface) pb.Response
+
+func (cc txChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+func (cc txChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return cc(stub)
+}
+
+func TestTransactionWrites(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        txChaincode
+		wantValue string // committed value of k0
+		wantRead  string // value of k0 read in the transaction
+		wantEvent string // name of the last event, empty means none
+	}{
+		{"reads don't see writes", func(stub shim.ChaincodeStubInterface) pb.Response {
+			stub.PutState("k0", []byte("written"))
+			value, _ := stub.GetState("k0")
+			return shim.Success(value)
+		}, "written", `{"n": 0}`, ""},
+		{"deletion is committed", func(stub shim.ChaincodeStubInterface) pb.Response {
+			stub.DelState("k0")
+			value, _ := stub.GetState("k0")
+			return shim.Success(value)
+		}, "", `{"n": 0}`, ""},
+		{"last event is kept", func(stub shim.ChaincodeStubInterface) pb.Response {
+			stub.SetEvent("first", nil)
+			stub.SetEvent("last", nil)
+			return shim.Success(nil)
+		}, `{"n": 0}`, "", "last"},
+		{"error discards writes and events", func(stub shim.ChaincodeStubInterface) pb.Response {
+			stub.PutState("k0", []byte("written"))
+			stub.SetEvent("event", nil)
+			return shim.Error("failed")
+		}, `{"n": 0}`, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFixtureStub(t, 1)
+			s.cc = tt.fn
+			res := s.MockInvoke("tx", [][]byte{[]byte("fn")})
+			if res.GetStatus() == shim.OK && string(res.GetPayload()) != tt.wantRead {
+				t.Errorf("read = %q, want %q", res.GetPayload(), tt.wantRead)
+			}
+			if value := string(s.State["k0"]); value != tt.wantValue {
+				t.Errorf("committed = %q, want %q", value, tt.wantValue)
+			}
+			event := ""
+			if len(s.Events) > 0 {
+				event = s.Events[len(s.Events)-1].EventName
+			}
+			if len(s.Events) > 1 || event != tt.wantEvent {
+				t.Errorf("events = %v, want [%s]", s.Events, tt.wantEvent)
+			}
+		})
+	}
+}